@@ -36,30 +36,149 @@ const (
 	RangedPatherAI
 )
 
+const (
+	// Intents - actions that monsters plan for their next turn.
+	IntentNone = iota
+	IntentMove
+	IntentMelee
+	IntentRanged
+)
+
+type Intent struct {
+	/* Intent is action chosen by monster at the end of turn, that will
+	   be performed during its next turn. It is shown on the board, so
+	   player knows exactly what will happen.
+	   DX, DY is direction of move or ranged attack;
+	   X, Y are coords of tile targeted by melee attack. */
+	Action int
+	DX, DY int
+	X, Y   int
+}
+
 func CreaturesTakeTurn(b Board, c Creatures) {
 	/* Function CreaturesTakeTurn is supposed to handle all enemy creatures
 	   actions: movement, attacking, etc.
 	   It takes Board and Creatures as arguments.
-	   Iterates through all Creatures slice, and calls HandleAI function with
-	   specific parameters.
-	   It skips NoAI and PlayerAI. */
-	var ai int
+	   It uses energy-based scheduler (see scheduler.go): at first, all
+	   monsters gain energy, then NextActor picks creatures one by one,
+	   until nobody has enough energy to act. Fast creatures may act
+	   more than once - they plan their next action right after
+	   the previous one. Stunned creatures lose their actions.
+	   It skips NoAI and PlayerAI.
+	   Effects tick once per turn, after all actions.
+	   When all creatures are done, they plan actions for the next turn. */
+	var actors = Creatures{}
 	for _, v := range c {
-		ai = v.AIType
-		if ai == NoAI || ai == PlayerAI {
+		if v.AIType == NoAI || v.AIType == PlayerAI || v.HPCurrent <= 0 {
+			continue
+		}
+		v.Energy += v.EnergyGain(c[0])
+		actors = append(actors, v)
+	}
+	for {
+		v := NextActor(actors)
+		if v == nil {
+			break
+		}
+		v.Energy -= ActionCost
+		if v.CanAct() == false {
 			continue
 		}
 		HandleAI(b, c, v)
+		if v.Energy >= ActionCost && v.HPCurrent > 0 {
+			v.PlanIntent(b, c)
+		}
+	}
+	for _, v := range actors {
+		v.TickEffects()
 	}
+	PlanIntents(b, c)
+}
+
+func (c *Creature) CanAct() bool {
+	/* CanAct checks if status effects allow receiver to take action. */
+	return c.HasEffect(EffectStunned) == false
 }
 
 func HandleAI(b Board, cs Creatures, c *Creature) {
-	/* HandleAI is small function that decides if monster will
-	   attack player or move towards him. */
-	if c.DistanceTo(cs[0].X, cs[0].Y) > 1 ||
-		(c.X != cs[0].X && c.Y != cs[0].Y) {
-		c.MoveTowardsPath(b, cs, cs[0].X, cs[0].Y)
-	} else {
-		c.AttackTarget(cs[0])
+	/* HandleAI is small function that executes action planned by monster
+	   at the end of previous turn. Monsters commit to their intents:
+	   melee attack hits only if player is still on targeted tile,
+	   and planned move is cancelled if destination is occupied. */
+	switch c.Intent.Action {
+	case IntentMove:
+		x, y := c.X+c.Intent.DX, c.Y+c.Intent.DY
+		if GetAliveCreatureFromTile(x, y, cs) == nil {
+			c.Move(c.Intent.DX, c.Intent.DY, b)
+		}
+	case IntentMelee:
+		if cs[0].X == c.Intent.X && cs[0].Y == c.Intent.Y {
+			c.AttackTarget(cs[0])
+		}
+	case IntentRanged:
+		c.RangedAttack(c.Intent.DX, c.Intent.DY, b, cs)
+	}
+	c.Intent = Intent{}
+}
+
+func PlanIntents(b Board, cs Creatures) {
+	/* Function PlanIntents is called at the end of every turn (and when
+	   new level is entered). Every living monster chooses its next
+	   action. Monsters that will not be able to act (because of status
+	   effects, or lack of energy), plan nothing. */
+	for _, v := range cs {
+		if v.AIType == NoAI || v.AIType == PlayerAI || v.HPCurrent <= 0 {
+			continue
+		}
+		if v.CanAct() == false || v.WillActNextTurn(cs[0]) == false {
+			v.Intent = Intent{}
+			continue
+		}
+		v.PlanIntent(b, cs)
+	}
+}
+
+func (c *Creature) PlanIntent(b Board, cs Creatures) {
+	/* PlanIntent decides what monster will do during its next turn.
+	   Ranged monsters shoot if player is in line of fire
+	   within Range, and there is nothing in the way.
+	   Melee monsters attack if player is adjacent (in cardinal directions).
+	   Otherwise, monster plans to move towards player. */
+	p := cs[0]
+	c.Intent = Intent{}
+	if c.AIType == RangedDumbAI || c.AIType == RangedPatherAI {
+		dx, dy, ok := c.LineOfFire(p, b, cs)
+		if ok == true {
+			c.Intent = Intent{IntentRanged, dx, dy, p.X, p.Y}
+			return
+		}
+	}
+	if c.DistanceTo(p.X, p.Y) <= 1 && (c.X == p.X || c.Y == p.Y) {
+		c.Intent = Intent{IntentMelee, p.X - c.X, p.Y - c.Y, p.X, p.Y}
+		return
+	}
+	dx, dy, err := c.PathTowards(b, cs, p.X, p.Y)
+	if err == nil {
+		c.Intent = Intent{IntentMove, dx, dy, c.X + dx, c.Y + dy}
+	}
+}
+
+func (c *Creature) LineOfFire(t *Creature, b Board, cs Creatures) (int, int, bool) {
+	/* LineOfFire checks if target "t" may be shot by receiver:
+	   it has to be in the same row or column, within receiver's Range,
+	   and it has to be the first creature on the line of fire.
+	   Returns direction of shot. */
+	if c.X != t.X && c.Y != t.Y {
+		return 0, 0, false
+	}
+	if c.DistanceTo(t.X, t.Y) > c.Range {
+		return 0, 0, false
+	}
+	dx, dy := Sign(t.X-c.X), Sign(t.Y-c.Y)
+	vec := FireVector(c.X, c.Y, dx, dy, c.Range)
+	TraceVector(vec, b, cs)
+	if len(vec.Hits) == 0 || vec.Hits[0] != t {
+		return 0, 0, false
 	}
+	return dx, dy, true
 }
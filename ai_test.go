@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import "testing"
+
+func TestLineOfFire(t *testing.T) {
+	/* Target stands on 5, 5; shooter has Range 3. Wall or
+	   other creature may be placed on ox, oy. */
+	var tests = []struct {
+		name     string
+		x, y     int
+		obstacle string
+		ox, oy   int
+		wantDX   int
+		wantDY   int
+		wantOK   bool
+	}{
+		{"west", 8, 5, "", 0, 0, -1, 0, true},
+		{"north", 5, 2, "", 0, 0, 0, 1, true},
+		{"adjacent", 5, 6, "", 0, 0, 0, -1, true},
+		{"out of range", 9, 5, "", 0, 0, 0, 0, false},
+		{"not in line", 7, 7, "", 0, 0, 0, 0, false},
+		{"wall in the way", 8, 5, "wall", 6, 5, 0, 0, false},
+		{"creature in the way", 8, 5, "creature", 7, 5, 0, 0, false},
+		{"creature behind target", 8, 5, "creature", 4, 5, -1, 0, true},
+	}
+	for _, tt := range tests {
+		b := testBoard()
+		p := testFighter(5, 5, 10, 0)
+		c := testFighter(tt.x, tt.y, 10, 0)
+		c.Range = 3
+		other := testFighter(1, 1, 10, 0)
+		switch tt.obstacle {
+		case "wall":
+			b[tt.ox][tt.oy].Blocked = true
+		case "creature":
+			other.X, other.Y = tt.ox, tt.oy
+		}
+		dx, dy, ok := c.LineOfFire(p, b, Creatures{p, c, other})
+		if dx != tt.wantDX || dy != tt.wantDY || ok != tt.wantOK {
+			t.Errorf("%s: LineOfFire = %d, %d, %v; want %d, %d, %v", tt.name,
+				dx, dy, ok, tt.wantDX, tt.wantDY, tt.wantOK)
+		}
+	}
+}
+
+func TestPlanIntent(t *testing.T) {
+	/* Player stands on 5, 5; ranged monsters have Range 3.
+	   Direction of planned move depends on pathfinding, so
+	   move is only checked to get monster closer to player. */
+	var tests = []struct {
+		name       string
+		ai         int
+		x, y       int
+		wantAction int
+		wantDX     int
+		wantDY     int
+	}{
+		{"melee adjacent", MeleePatherAI, 6, 5, IntentMelee, -1, 0},
+		{"melee diagonal moves", MeleePatherAI, 6, 6, IntentMove, 0, 0},
+		{"melee far away moves", MeleeDumbAI, 9, 5, IntentMove, 0, 0},
+		{"ranged in line", RangedPatherAI, 8, 5, IntentRanged, -1, 0},
+		{"ranged adjacent shoots", RangedDumbAI, 5, 4, IntentRanged, 0, 1},
+		{"ranged out of range moves", RangedPatherAI, 9, 5, IntentMove, 0, 0},
+		{"ranged not in line moves", RangedPatherAI, 7, 7, IntentMove, 0, 0},
+	}
+	for _, tt := range tests {
+		b := testBoard()
+		p := testFighter(5, 5, 10, 0)
+		p.AIType = PlayerAI
+		c := testFighter(tt.x, tt.y, 10, 0)
+		c.AIType, c.Range = tt.ai, 3
+		c.PlanIntent(b, Creatures{p, c})
+		if c.Intent.Action != tt.wantAction {
+			t.Errorf("%s: action = %d, want %d", tt.name,
+				c.Intent.Action, tt.wantAction)
+			continue
+		}
+		if tt.wantAction == IntentMove {
+			x, y := c.X+c.Intent.DX, c.Y+c.Intent.DY
+			before := AbsoluteValue(p.X-c.X) + AbsoluteValue(p.Y-c.Y)
+			after := AbsoluteValue(p.X-x) + AbsoluteValue(p.Y-y)
+			if after >= before {
+				t.Errorf("%s: move to %d, %d does not get closer", tt.name, x, y)
+			}
+			continue
+		}
+		if c.Intent.DX != tt.wantDX || c.Intent.DY != tt.wantDY ||
+			c.Intent.X != p.X || c.Intent.Y != p.Y {
+			t.Errorf("%s: intent = %+v, want %d, %d towards %d, %d", tt.name,
+				c.Intent, tt.wantDX, tt.wantDY, p.X, p.Y)
+		}
+	}
+}
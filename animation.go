@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	blt "bearlibterminal"
+)
+
+const (
+	// Animation speed values, set by ANIMATIONS option.
+	AnimationsOff = iota
+	AnimationsFast
+	AnimationsNormal
+)
+
+const (
+	// Delay between frames of animations, in milliseconds.
+	ProjectileDelay = 30
+	FlashDelay      = 60
+	BurstDelay      = 60
+	ChainDelay      = 80
+)
+
+const (
+	// Glyphs used by animations.
+	FlashChar     = "*"
+	FlashColor    = "white"
+	BurstChar     = "·"
+	ProjectileRay = "·"
+)
+
+// Names of animation speeds, as used in options_controls.cfg.
+var AnimationNames = []string{"OFF", "FAST", "NORMAL"}
+
+var AnimationSpeed = AnimationsNormal
+
+/* Headless is true as long as game runs without window - until
+   InitializeBLT opens it, so also when checking config, or in tests;
+   animations and delays are skipped then. */
+var Headless = true
+
+type Cell struct {
+	/* Cell is single glyph of animation frame. */
+	X, Y  int
+	Char  string
+	Color string
+}
+
+type Frame struct {
+	/* Frame is set of cells drawn at once; Delay is time (in
+	   milliseconds) for which frame stays on screen. */
+	Cells []Cell
+	Delay int
+}
+
+// Animation is sequence of frames played one after another.
+type Animation []Frame
+
+func AnimationDelay(delay int) int {
+	/* Function AnimationDelay scales delay according to animation speed. */
+	if AnimationSpeed == AnimationsFast {
+		return delay / 3
+	}
+	return delay
+}
+
+func PlayAnimation(a Animation, layer int) {
+	/* Function PlayAnimation draws frames of animation on layer,
+	   over already rendered board. Every frame clears previous one.
+	   At the end, layer is cleared, so the next RenderAll will show
+	   the aftermath. Animations are skipped if disabled,
+	   or in headless mode. */
+	if AnimationSpeed == AnimationsOff || Headless == true || len(a) == 0 {
+		return
+	}
+	blt.Layer(layer)
+	for _, frame := range a {
+		blt.ClearArea(0, 0, MapSizeX, MapSizeY)
+		for _, v := range frame.Cells {
+			blt.Print(v.X, v.Y, "[color="+v.Color+"]"+v.Char+"[/color]")
+		}
+		blt.Refresh()
+		blt.Delay(AnimationDelay(frame.Delay))
+	}
+	blt.Layer(layer)
+	blt.ClearArea(0, 0, MapSizeX, MapSizeY)
+	blt.Refresh()
+}
+
+func ProjectileAnimation(vec *Vector, endX, endY int, char, color string) Animation {
+	/* Function ProjectileAnimation returns animation of projectile
+	   travelling along vector, from the first tile after start,
+	   to endX, endY. */
+	var a = Animation{}
+	for i := range vec.TilesX {
+		x, y := vec.TilesX[i], vec.TilesY[i]
+		if x == vec.StartX && y == vec.StartY {
+			continue
+		}
+		a = append(a, Frame{[]Cell{{x, y, char, color}}, ProjectileDelay})
+		if x == endX && y == endY {
+			break
+		}
+	}
+	return a
+}
+
+func HitFlashAnimation(x, y int) Animation {
+	/* Function HitFlashAnimation returns short flash on damaged creature. */
+	return Animation{
+		Frame{[]Cell{{x, y, FlashChar, FlashColor}}, FlashDelay},
+	}
+}
+
+func DeathBurstAnimation(x, y int, color string) Animation {
+	/* Function DeathBurstAnimation returns burst of particles around
+	   dying creature: the first frame is flash in the center, the second
+	   one - particles on all adjacent tiles. */
+	var particles = []Cell{}
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			px, py := x+dx, y+dy
+			if (dx == 0 && dy == 0) ||
+				px < 0 || px >= MapSizeX || py < 0 || py >= MapSizeY {
+				continue
+			}
+			particles = append(particles, Cell{px, py, BurstChar, color})
+		}
+	}
+	return Animation{
+		Frame{[]Cell{{x, y, FlashChar, color}}, BurstDelay},
+		Frame{particles, BurstDelay},
+	}
+}
+
+func ChainAnimation(xs, ys []int, icon, color string) Animation {
+	/* Function ChainAnimation returns chain lightning drawn link by link;
+	   every frame contains all links drawn so far. */
+	var a = Animation{}
+	var cells = []Cell{}
+	for i := range xs {
+		cells = append(cells, Cell{xs[i], ys[i], icon, color})
+		frame := make([]Cell, len(cells))
+		copy(frame, cells)
+		a = append(a, Frame{frame, ChainDelay})
+	}
+	return a
+}
+
+func Pause(delay int) {
+	/* Function Pause is blt.Delay that respects animation settings.
+	   It is used for pauses that let player notice what happened. */
+	if AnimationSpeed == AnimationsOff || Headless == true {
+		return
+	}
+	blt.Delay(AnimationDelay(delay))
+}
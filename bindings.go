@@ -0,0 +1,174 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	blt "bearlibterminal"
+)
+
+const (
+	// Entries of BindingsScreen, other than actions.
+	BindingsLayout = "Layout"
+	BindingsCustom = "Custom keys"
+	BindingsSave   = "Save"
+)
+
+type BindingsScreen struct {
+	/* BindingsScreen is editor of custom controls. It lists all
+	   actions with keys bound to them, allows to add new key
+	   (or key combo) and to clear keys of action, to switch
+	   keyboard layout, and to save everything to
+	   options_controls.cfg. Status is the last message shown
+	   at the bottom of screen. */
+	Menu   ListMenu
+	Status string
+}
+
+func (s *BindingsScreen) Update() {
+	s.Menu.Items = []string{BindingsLayout, BindingsCustom}
+	s.Menu.Values = []string{LayoutNames[KeyboardLayout], "FALSE"}
+	if CustomControls == true {
+		s.Menu.Values[1] = "TRUE"
+	}
+	for _, v := range Actions {
+		s.Menu.Items = append(s.Menu.Items, v)
+		s.Menu.Values = append(s.Menu.Values, CustomKeyNames(v))
+	}
+	s.Menu.Items = append(s.Menu.Items, BindingsSave)
+	s.Menu.Values = append(s.Menu.Values, "")
+	s.Menu.X, s.Menu.Y, s.Menu.W = 1, 1, WindowSizeX-2
+	s.draw()
+	key := ReadInput()
+	switch key {
+	case blt.TK_ESCAPE:
+		Screens.Pop()
+		return
+	case blt.TK_CLOSE:
+		Screens.Clear()
+		return
+	case blt.TK_BACKSPACE, blt.TK_DELETE:
+		s.clear(s.Menu.Items[s.Menu.Cursor])
+		return
+	}
+	if s.Menu.HandleKey(key) == false {
+		return
+	}
+	switch item := s.Menu.Items[s.Menu.Cursor]; item {
+	case BindingsLayout:
+		KeyboardLayout = (KeyboardLayout + 1) % len(LayoutNames)
+		ChooseKeyboardLayout()
+	case BindingsCustom:
+		CustomControls = !CustomControls
+	case BindingsSave:
+		s.save()
+	default:
+		s.bind(item)
+	}
+}
+
+func (s *BindingsScreen) draw() {
+	/* draw prints menu on the screen. Menu is scrolled
+	   if it does not fit in the window. */
+	blt.Clear()
+	blt.Layer(UILayer)
+	height := WindowSizeY - 3
+	y := 1
+	if s.Menu.Cursor >= height {
+		y = y - (s.Menu.Cursor - height + 1)
+	}
+	s.Menu.Y = y
+	s.Menu.Draw()
+	blt.ClearArea(0, 0, WindowSizeX, 1)
+	blt.ClearArea(0, WindowSizeY-2, WindowSizeX, 2)
+	PrintCentered(0, "[color=white]Key bindings[/color]")
+	if s.Status != "" {
+		PrintHint(WindowSizeY-2, EscapeBltString(s.Status))
+	}
+	PrintHint(WindowSizeY-1, "Enter: add key; Del: clear; Esc: back")
+	blt.Refresh()
+}
+
+func (s *BindingsScreen) bind(action string) {
+	/* bind waits for new key for action; modifiers held down
+	   are part of the key. Reserved keys are refused; key already
+	   used by other action is rebound only if player confirms it. */
+	s.Status = "Press new key for " + action + "; Esc: cancel."
+	s.draw()
+	key := 0
+	for key == 0 {
+		// Modifiers pressed alone are not keys.
+		key = ReadInput()
+	}
+	if key == blt.TK_ESCAPE || key == blt.TK_CLOSE {
+		s.Status = ""
+		return
+	}
+	key = KeyWithModifiers(key)
+	name := KeyName(key)
+	if reserved, ok := ReservedKeys[key]; ok == true {
+		s.Status = name + " is reserved for " + reserved + "."
+		return
+	}
+	if other := CustomCommandKeys[key]; other == action {
+		s.Status = name + " is bound to " + action + " already."
+		return
+	} else if other != "" {
+		if Confirm(name+" is already bound to "+other+". Bind it to "+
+			action+" instead?") == false {
+			s.Status = ""
+			return
+		}
+	}
+	CustomCommandKeys[key] = action
+	s.Status = action + " = " + CustomKeyNames(action)
+}
+
+func (s *BindingsScreen) clear(action string) {
+	/* clear removes all keys bound to action. */
+	for k, v := range CustomCommandKeys {
+		if v == action {
+			delete(CustomCommandKeys, k)
+		}
+	}
+}
+
+func (s *BindingsScreen) save() {
+	/* save writes options_controls.cfg. Actions that have keys in
+	   the default controls scheme would get these keys back after
+	   restart, so they have to be bound before saving. */
+	for _, v := range Actions {
+		if CustomKeyNames(v) == "" && keyNamesIn(CommandKeys, v) != "" {
+			s.Status = v + " has no key; bind it before saving."
+			return
+		}
+	}
+	if err := SaveOptionsControls(); err != nil {
+		s.Status = "Can not save config: " + err.Error()
+		return
+	}
+	s.Status = "Saved to " + OptionsControlsPath + "."
+}
@@ -27,20 +27,38 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
 )
 
 const (
-	// Damage types.
-	BallisticDMG = iota
-	ExplosiveDMG
-	KineticDMG
-	ElectromagneticDMG
+	// Affinities - how creature reacts to specific damage type.
+	AffinityNeutral = iota
+	AffinityWeak
+	AffinityResistant
+	AffinityImmune
 )
 
+// Damage multipliers table, indexed by affinity.
+var DamageMultipliers = map[int]float64{
+	AffinityNeutral:   1.0,
+	AffinityWeak:      2.0,
+	AffinityResistant: 0.5,
+	AffinityImmune:    0.0,
+}
+
 func (c *Creature) AttackTarget(t *Creature) {
-	/* Receiver "c" is attacker, argument "t" is target. */
+	/* Receiver "c" is attacker, argument "t" is target.
+	   Attacker's AttackEffect (if any) is put on target that
+	   survived the attack. */
+	AddMessage(c.LogName()+" attacks "+t.LogName()+".", MessageColorInfo)
 	t.TakeDamage(c.Attack - t.Defense)
+	if c.AttackEffect != "" {
+		t.ApplyEffect(c.AttackEffect, 0)
+	}
 }
 
 func (c *Creature) Shoot(dx, dy int, b Board, cs Creatures) bool {
@@ -49,56 +67,343 @@ func (c *Creature) Shoot(dx, dy int, b Board, cs Creatures) bool {
 	   player's method. */
 	turnSpent := false
 	// shoot in cardinal directions only
-	tx, ty := c.X, c.Y
-	if dx == (-1) {
+	vec := FireVector(c.X, c.Y, dx, dy, MapSizeX+MapSizeY)
+	TraceVector(vec, b, cs)
+	var target *Creature
+	if len(vec.Hits) > 0 {
+		target = vec.Hits[0]
+	}
+	tile := vec.Obstacle
+	if c.Active < 0 || c.Active >= len(DamageTypes) {
+		return turnSpent
+	}
+	activeAttack := DamageTypes[c.Active]
+	if c.Ammo[activeAttack.Name] <= 0 {
+		AddMessage("no "+activeAttack.Name+" ammo.", MessageColorBad)
+		return turnSpent
+	}
+	c.Ammo[activeAttack.Name]--
+	turnSpent = true
+	endX, endY := ImpactPoint(vec, tile, target)
+	if activeAttack.Behavior == BehaviorPierce && len(vec.Hits) > 0 {
+		endX, endY = ImpactPoint(vec, tile, nil)
+	}
+	PlayAnimation(ProjectileAnimation(vec, endX, endY,
+		activeAttack.Icon, activeAttack.ColorGood), OverlayLayer)
+	switch activeAttack.Behavior {
+	case BehaviorExplode:
+		x, y := ImpactPoint(vec, tile, target)
+		c.Explode(x, y, activeAttack, b, cs)
+	case BehaviorKnockback:
+		if target != nil {
+			c.Hit(target, activeAttack, 1.0)
+			if target.DamageMultiplier(activeAttack.Name) > 0 {
+				// Immune targets are not pushed.
+				target.Knockback(dx, dy, activeAttack, b, cs)
+			}
+		}
+	case BehaviorPierce:
+		c.Pierce(vec.Hits, activeAttack)
+	case BehaviorChain:
+		if target != nil {
+			c.Hit(target, activeAttack, 1.0)
+			target.Electrocute()
+			c.Chain(target, activeAttack, cs)
+		}
+	default:
+		if target != nil {
+			c.Hit(target, activeAttack, 1.0)
+		}
+	}
+	return turnSpent
+}
+
+func FireVector(sx, sy, dx, dy, rng int) *Vector {
+	/* Function FireVector returns computed Vector that starts on sx, sy
+	   and goes in dx, dy direction (cardinal directions only), up to rng
+	   tiles, or to the map edge. */
+	tx, ty := sx+dx*rng, sy+dy*rng
+	if tx < 0 {
 		tx = 0
-	} else if dx == 1 {
+	} else if tx > MapSizeX-1 {
 		tx = MapSizeX - 1
-	} else if dy == (-1) {
+	}
+	if ty < 0 {
 		ty = 0
-	} else if dy == 1 {
+	} else if ty > MapSizeY-1 {
 		ty = MapSizeY - 1
 	}
-	vec, err := NewVector(c.X, c.Y, tx, ty)
+	vec, err := NewVector(sx, sy, tx, ty)
 	if err != nil {
 		fmt.Println(err)
 	}
 	_ = ComputeVector(vec)
-	_, _, target := ValidateVector(vec, b, cs)
-	var attacks = []int{
-		BallisticDMG, ExplosiveDMG, KineticDMG, ElectromagneticDMG}
-	activeAttack := attacks[c.Active]
-	// this switch checks if player's attack matches target vulnerability
-	switch {
-	case activeAttack == BallisticDMG && c.Ballistic > 0:
-		c.Ballistic--
-	case activeAttack == ExplosiveDMG && c.Explosive > 0:
-		c.Explosive--
-	case activeAttack == KineticDMG && c.Kinetic > 0:
-		c.Kinetic--
-	case activeAttack == ElectromagneticDMG && c.Electromagnetic > 0:
-		c.Electromagnetic--
-	default:
-		return turnSpent
+	return vec
+}
+
+func (c *Creature) RangedAttack(dx, dy int, b Board, cs Creatures) {
+	/* RangedAttack is simple ranged attack used by monsters. Unlike
+	   Shoot, it does not use ammo nor damage types; the first creature
+	   on the line of fire (within Range) is attacked, no matter if it is
+	   player or other monster. */
+	vec := FireVector(c.X, c.Y, dx, dy, c.Range)
+	TraceVector(vec, b, cs)
+	var target *Creature
+	if len(vec.Hits) > 0 {
+		target = vec.Hits[0]
 	}
-	turnSpent = true
+	endX, endY := ImpactPoint(vec, vec.Obstacle, target)
+	PlayAnimation(ProjectileAnimation(vec, endX, endY,
+		ProjectileRay, IntentRangedColor), OverlayLayer)
+	if target != nil {
+		c.AttackTarget(target)
+	}
+}
+
+func ImpactPoint(vec *Vector, tile *Tile, target *Creature) (int, int) {
+	/* Function ImpactPoint returns coords where shot ends. It takes
+	   values returned by ValidateVector: the first creature on the way
+	   has priority, then blocking tile; if shot is not stopped by
+	   anything, it ends on the last tile of vector (ie map edge).
+	   Empty vector ends where it starts. */
 	if target != nil {
-		if (activeAttack == BallisticDMG && target.Ballistic > 0) ||
-			(activeAttack == ExplosiveDMG && target.Explosive > 0) ||
-			(activeAttack == KineticDMG && target.Kinetic > 0) ||
-			(activeAttack == ElectromagneticDMG && target.Electromagnetic > 0) {
-			target.TakeDamage((c.Attack - target.Defense) * 2)
+		return target.X, target.Y
+	}
+	if tile != nil {
+		return tile.X, tile.Y
+	}
+	if len(vec.TilesX) == 0 || len(vec.TilesY) == 0 {
+		return vec.StartX, vec.StartY
+	}
+	last := len(vec.TilesX) - 1
+	return vec.TilesX[last], vec.TilesY[last]
+}
+
+func (c *Creature) Explode(x, y int, dt DamageType, b Board, cs Creatures) {
+	/* Explode is method that has attacker as receiver. It takes
+	   coords of detonation point, damage type, level map, and all creatures.
+	   Every living creature within damage type Radius takes damage
+	   (including attacker!), and every wall in blast radius is turned
+	   into floor - except walls on the map edge, that keep creatures
+	   within map bounds. Tiles are changed in place, so all Boards that share
+	   them are updated as well; pathfinding builds its graph from
+	   Board every turn, so monsters will use new passages immediately. */
+	for tx := x - dt.Radius; tx <= x+dt.Radius; tx++ {
+		for ty := y - dt.Radius; ty <= y+dt.Radius; ty++ {
+			if tx < 0 || tx >= MapSizeX || ty < 0 || ty >= MapSizeY {
+				continue
+			}
+			if DistanceBetween(x, y, tx, ty) > dt.Radius {
+				continue
+			}
+			edge := tx == 0 || tx == MapSizeX-1 || ty == 0 || ty == MapSizeY-1
+			if b[tx][ty].Blocked == true && edge == false {
+				b[tx][ty].MakeFloor()
+			}
+			t := GetAliveCreatureFromTile(tx, ty, cs)
+			if t != nil {
+				c.Hit(t, dt, 1.0)
+			}
 		}
 	}
-	return turnSpent
+}
+
+func (c *Creature) Hit(t *Creature, dt DamageType, multiplier float64) {
+	/* Hit is method that has attacker as receiver. Target "t" takes
+	   damage of type dt, multiplied by multiplier (used by chains and
+	   piercing shots). Multiplied damage is rounded up, and every hit
+	   that deals damage at all deals at least 1 - otherwise weak
+	   attacks would be lost on every jump or pierce.
+	   If damage type carries status effect, it is
+	   applied to target, unless target is immune to that damage type. */
+	dmg := c.DamageAgainst(t, dt.Name)
+	if dmg > 0 && multiplier != 1.0 {
+		dmg = int(math.Ceil(float64(dmg) * multiplier))
+		if dmg < 1 {
+			dmg = 1
+		}
+	}
+	if t.DamageMultiplier(dt.Name) == 0 {
+		AddMessage(t.LogName()+" is immune to "+dt.Name+".", MessageColorBad)
+	}
+	t.TakeDamage(dmg)
+	if dt.Effect != "" && t.DamageMultiplier(dt.Name) > 0 {
+		t.ApplyEffect(dt.Effect, dt.EffectDuration)
+	}
+}
+
+func (c *Creature) DamageAgainst(t *Creature, dmgType string) int {
+	/* DamageAgainst computes damage that receiver would deal to
+	   target "t" using specified damage type. Base damage is
+	   modified by target's affinity to that damage type (check
+	   DamageMultipliers table); fractions are dropped.
+	   It never returns negative values. */
+	dmg := c.Attack - t.Defense
+	if dmg < 0 {
+		dmg = 0
+	}
+	return int(float64(dmg) * t.DamageMultiplier(dmgType))
+}
+
+func (c *Creature) DamageMultiplier(dmgType string) float64 {
+	/* DamageMultiplier returns multiplier of damage of given type
+	   taken by receiver. Creatures without affinities defined
+	   (like player) are neutral to every damage type. */
+	return DamageMultipliers[c.Affinities[dmgType]]
+}
+
+func (c *Creature) SetAffinities() error {
+	/* SetAffinities fills Affinities map using names of damage types
+	   stored in Weaknesses, Resistances and Immunities.
+	   Resistances are applied first, then Immunities, then Weaknesses,
+	   so weakness always wins - it allows to create monsters immune
+	   to everything except one randomly chosen damage type.
+	   RandomWeaknesses picks damage types that are not
+	   weaknesses already; rolled names are appended to Weaknesses.
+	   Returns error if unknown damage type name is used. */
+	var err error
+	c.Affinities = map[string]int{}
+	var lists = [][]string{c.Resistances, c.Immunities, c.Weaknesses}
+	var affinities = []int{AffinityResistant, AffinityImmune, AffinityWeak}
+	for i, list := range lists {
+		for _, v := range list {
+			if _, ok := DamageTypeByName(v); ok == false {
+				txt := DamageTypeNameError(v)
+				err = errors.New("Unknown damage type." + txt)
+				continue
+			}
+			c.Affinities[v] = affinities[i]
+		}
+	}
+	for i := 0; i < c.RandomWeaknesses; i++ {
+		var candidates = []string{}
+		for _, v := range DamageTypes {
+			if c.Affinities[v.Name] != AffinityWeak {
+				candidates = append(candidates, v.Name)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		dmgType := candidates[rand.Intn(len(candidates))]
+		c.Affinities[dmgType] = AffinityWeak
+		c.Weaknesses = append(c.Weaknesses, dmgType)
+	}
+	return err
+}
+
+func (c *Creature) Knockback(dx, dy int, dt DamageType, b Board, cs Creatures) {
+	/* Knockback is method that pushes receiver by dt.Knockback tiles
+	   in dx, dy direction (ie along the firing vector).
+	   Every step is done by Move method, so it respects map bounds and
+	   blocking tiles, and resolves hazards of every tile entered.
+	   If receiver is stopped by wall or map edge, it takes
+	   CollisionDamage; if it hits other creature, both of them
+	   take CollisionDamage. */
+	for i := 0; i < dt.Knockback; i++ {
+		if c.HPCurrent <= 0 {
+			break
+		}
+		x, y := c.X+dx, c.Y+dy
+		other := GetAliveCreatureFromTile(x, y, cs)
+		if other != nil {
+			other.TakeDamage(dt.CollisionDamage)
+			c.TakeDamage(dt.CollisionDamage)
+			break
+		}
+		if c.CanMoveTo(x, y, b) == false {
+			c.TakeDamage(dt.CollisionDamage)
+			break
+		}
+		c.Move(dx, dy, b)
+	}
+}
+
+func (c *Creature) Pierce(hits Creatures, dt DamageType) {
+	/* Pierce is method that has attacker as receiver. Shot passes
+	   through dt.Pierce creatures, so it may hit up to dt.Pierce+1
+	   targets, in order they were met on the line of fire.
+	   After every creature, damage is multiplied by dt.PierceFalloff. */
+	multiplier := 1.0
+	for i, v := range hits {
+		if i > dt.Pierce {
+			break
+		}
+		c.Hit(v, dt, multiplier)
+		multiplier *= dt.PierceFalloff
+	}
+}
+
+func (c *Creature) Chain(t *Creature, dt DamageType, cs Creatures) {
+	/* Chain is method that has attacker as receiver; "t" is the creature
+	   hit by shot. Lightning arcs from "t" to adjacent living creature
+	   (other than attacker) that was not hit yet, up to dt.ChainLength
+	   times. Damage is multiplied by dt.ChainMultiplier on every jump.
+	   Creatures are checked in order of Creatures slice, so chain is
+	   deterministic. At the end, chain is drawn on the screen. */
+	var hit = Creatures{t}
+	var xs = []int{t.X}
+	var ys = []int{t.Y}
+	current := t
+	multiplier := 1.0
+	for i := 0; i < dt.ChainLength; i++ {
+		multiplier *= dt.ChainMultiplier
+		var next *Creature
+		for _, v := range cs {
+			if v == c || v.HPCurrent <= 0 || CreatureIsInSlice(v, hit) {
+				continue
+			}
+			if AbsoluteValue(v.X-current.X) <= 1 &&
+				AbsoluteValue(v.Y-current.Y) <= 1 {
+				next = v
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.Hit(next, dt, multiplier)
+		next.Electrocute()
+		hit = append(hit, next)
+		xs = append(xs, next.X)
+		ys = append(ys, next.Y)
+		current = next
+	}
+	PlayAnimation(ChainAnimation(xs, ys, dt.Icon, dt.ColorGood), OverlayLayer)
+}
+
+func (c *Creature) Electrocute() {
+	/* Electrocute stuns machines for one turn.
+	   Other creatures are not affected. */
+	if c.HasTag(TagMachine) == true {
+		c.ApplyEffect(EffectStunned, 1)
+	}
 }
 
 func (c *Creature) TakeDamage(dmg int) {
 	/* Method TakeDamage has *Creature as receiver and takes damage integer
-	   as argument. dmg value is deducted from Creature current HP.
+	   as argument. dmg value is deducted from Creature current HP,
+	   after shield (if any) absorbs its part.
 	   If HPCurrent is below zero after taking damage, Creature dies. */
+	absorbed := c.AbsorbDamage(dmg)
+	if absorbed < dmg {
+		AddMessage("shield absorbs "+strconv.Itoa(dmg-absorbed)+" damage.",
+			MessageColorInfo)
+	}
+	dmg = absorbed
+	if dmg <= 0 {
+		return
+	}
+	color := MessageColorInfo
+	if c.AIType == PlayerAI {
+		color = MessageColorBad
+	}
+	AddMessage(c.LogName()+" takes "+strconv.Itoa(dmg)+" damage.", color)
 	c.HPCurrent -= dmg
 	if c.HPCurrent <= 0 {
+		PlayAnimation(DeathBurstAnimation(c.X, c.Y, c.Color), OverlayLayer)
 		c.Die()
+	} else {
+		PlayAnimation(HitFlashAnimation(c.X, c.Y), OverlayLayer)
 	}
 }
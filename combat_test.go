@@ -0,0 +1,381 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetAffinities(t *testing.T) {
+	defer func(old []DamageType) { DamageTypes = old }(DamageTypes)
+	DamageTypes = []DamageType{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	var tests = []struct {
+		name        string
+		resistances []string
+		immunities  []string
+		weaknesses  []string
+		want        map[string]int
+		wantErr     bool
+	}{
+		{"one of each", []string{"a"}, []string{"b"}, []string{"c"},
+			map[string]int{"a": AffinityResistant, "b": AffinityImmune,
+				"c": AffinityWeak}, false},
+		{"weakness wins", []string{"a"}, []string{"a", "b", "c"}, []string{"b"},
+			map[string]int{"a": AffinityImmune, "b": AffinityWeak,
+				"c": AffinityImmune}, false},
+		{"unknown damage type", []string{"x"}, nil, []string{"c"},
+			map[string]int{"c": AffinityWeak}, true},
+		{"neutral", nil, nil, nil, map[string]int{}, false},
+	}
+	for _, tt := range tests {
+		c := &Creature{}
+		c.Resistances, c.Immunities, c.Weaknesses = tt.resistances,
+			tt.immunities, tt.weaknesses
+		err := c.SetAffinities()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error: %v", tt.name, err, tt.wantErr)
+		}
+		if reflect.DeepEqual(c.Affinities, tt.want) == false {
+			t.Errorf("%s: Affinities = %v, want %v", tt.name, c.Affinities, tt.want)
+		}
+	}
+}
+
+func TestSetAffinitiesRandomWeaknesses(t *testing.T) {
+	/* Random weaknesses are rolled only among damage types that are
+	   not weaknesses already, so they never repeat. */
+	defer func(old []DamageType) { DamageTypes = old }(DamageTypes)
+	DamageTypes = []DamageType{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	var tests = []struct {
+		weaknesses []string
+		random     int
+		wantWeak   int
+	}{
+		{nil, 1, 1},
+		{[]string{"a"}, 1, 2},
+		{[]string{"a"}, 5, 3},
+		{nil, 0, 0},
+	}
+	for _, tt := range tests {
+		c := &Creature{}
+		c.Immunities = []string{"a", "b", "c"}
+		c.Weaknesses = append([]string{}, tt.weaknesses...)
+		c.RandomWeaknesses = tt.random
+		c.SetAffinities()
+		weak := 0
+		for _, v := range c.Affinities {
+			if v == AffinityWeak {
+				weak++
+			}
+		}
+		if weak != tt.wantWeak || len(c.Weaknesses) != tt.wantWeak {
+			t.Errorf("%v + %d random: %d weak affinities, %d weaknesses; want %d",
+				tt.weaknesses, tt.random, weak, len(c.Weaknesses), tt.wantWeak)
+		}
+	}
+}
+
+func testBoard() Board {
+	/* testBoard returns map with floor everywhere,
+	   except walls on the map edge. */
+	b := InitializeEmptyMap()
+	for x := 1; x < MapSizeX-1; x++ {
+		for y := 1; y < MapSizeY-1; y++ {
+			b[x][y].MakeFloor()
+		}
+	}
+	return b
+}
+
+func testFighter(x, y, hp, attack int) *Creature {
+	c := &Creature{}
+	c.Name, c.X, c.Y, c.HPMax, c.HPCurrent, c.Attack = "fighter", x, y,
+		hp, hp, attack
+	c.Blocked = true
+	return c
+}
+
+func TestExplode(t *testing.T) {
+	/* Attacker stands on 1, 1; it is hit by its own blast
+	   only if it is within radius. */
+	var tests = []struct {
+		name       string
+		x, y       int
+		radius     int
+		tx, ty     int
+		wx, wy     int
+		wantHP     int
+		wantSelfHP int
+		wantWall   bool
+	}{
+		{"target and wall in radius", 5, 5, 1, 6, 5, 5, 6, 3, 5, false},
+		{"diagonal is in radius", 5, 5, 1, 6, 6, 4, 4, 3, 5, false},
+		{"out of radius", 5, 5, 1, 7, 5, 5, 7, 5, 5, true},
+		{"attacker hits itself", 2, 1, 1, 3, 1, 2, 2, 3, 3, false},
+		{"map edge stays", 1, 5, 2, 2, 5, 0, 5, 3, 5, true},
+	}
+	for _, tt := range tests {
+		b := testBoard()
+		b[tt.wx][tt.wy].Blocked = true
+		c := testFighter(1, 1, 5, 2)
+		target := testFighter(tt.tx, tt.ty, 5, 0)
+		dt := DamageType{Name: "explosive", Behavior: BehaviorExplode,
+			Radius: tt.radius}
+		c.Explode(tt.x, tt.y, dt, b, Creatures{c, target})
+		if target.HPCurrent != tt.wantHP {
+			t.Errorf("%s: target HP = %d, want %d", tt.name,
+				target.HPCurrent, tt.wantHP)
+		}
+		if c.HPCurrent != tt.wantSelfHP {
+			t.Errorf("%s: attacker HP = %d, want %d", tt.name,
+				c.HPCurrent, tt.wantSelfHP)
+		}
+		if b[tt.wx][tt.wy].Blocked != tt.wantWall {
+			t.Errorf("%s: wall on %d, %d blocked = %v, want %v", tt.name,
+				tt.wx, tt.wy, b[tt.wx][tt.wy].Blocked, tt.wantWall)
+		}
+	}
+}
+
+func TestKnockback(t *testing.T) {
+	/* Target starts on 5, 5 and is pushed two tiles east.
+	   Wall, other creature or hazard is placed on ox, oy;
+	   map edge is checked by target that starts next to it. */
+	var tests = []struct {
+		name        string
+		sx          int
+		obstacle    string
+		ox, oy      int
+		wantX       int
+		wantHP      int
+		wantOtherHP int
+		wantSlowed  bool
+	}{
+		{"free path", 5, "", 0, 0, 7, 5, 5, false},
+		{"wall after one step", 5, "wall", 7, 5, 6, 4, 5, false},
+		{"creature in the way", 5, "creature", 6, 5, 5, 4, 4, false},
+		{"map edge", MapSizeX - 2, "", 0, 0, MapSizeX - 2, 4, 5, false},
+		{"hazard on the way", 5, "hazard", 6, 5, 7, 4, 5, true},
+	}
+	for _, tt := range tests {
+		b := testBoard()
+		c := testFighter(1, 1, 5, 1)
+		target := testFighter(tt.sx, 5, 5, 0)
+		other := testFighter(1, 10, 5, 0)
+		switch tt.obstacle {
+		case "wall":
+			b[tt.ox][tt.oy].Blocked = true
+		case "creature":
+			other.X, other.Y = tt.ox, tt.oy
+		case "hazard":
+			b[tt.ox][tt.oy].Hazard = true
+		}
+		dt := DamageType{Name: "kinetic", Behavior: BehaviorKnockback,
+			Knockback: 2, CollisionDamage: 1}
+		target.Knockback(1, 0, dt, b, Creatures{c, target, other})
+		if target.X != tt.wantX || target.Y != 5 {
+			t.Errorf("%s: target on %d, %d, want %d, 5", tt.name,
+				target.X, target.Y, tt.wantX)
+		}
+		if target.HPCurrent != tt.wantHP || other.HPCurrent != tt.wantOtherHP {
+			t.Errorf("%s: HP = %d, other HP = %d; want %d, %d", tt.name,
+				target.HPCurrent, other.HPCurrent, tt.wantHP, tt.wantOtherHP)
+		}
+		if target.HasEffect(EffectSlowed) != tt.wantSlowed {
+			t.Errorf("%s: slowed = %v, want %v", tt.name,
+				target.HasEffect(EffectSlowed), tt.wantSlowed)
+		}
+	}
+}
+
+func TestHit(t *testing.T) {
+	var tests = []struct {
+		name       string
+		attack     int
+		affinity   int
+		multiplier float64
+		wantDmg    int
+		wantEffect bool
+	}{
+		{"neutral", 3, AffinityNeutral, 1.0, 3, true},
+		{"rounded up", 3, AffinityNeutral, 0.5, 2, true},
+		{"at least 1", 1, AffinityNeutral, 0.1, 1, true},
+		{"weak", 2, AffinityWeak, 0.5, 2, true},
+		{"resisted to 0", 1, AffinityResistant, 0.5, 0, true},
+		{"immune", 3, AffinityImmune, 1.0, 0, false},
+	}
+	for _, tt := range tests {
+		c := testFighter(1, 1, 10, tt.attack)
+		target := testFighter(2, 1, 10, 0)
+		target.Affinities = map[string]int{"a": tt.affinity}
+		dt := DamageType{Name: "a", Effect: EffectBurning}
+		c.Hit(target, dt, tt.multiplier)
+		if dmg := 10 - target.HPCurrent; dmg != tt.wantDmg {
+			t.Errorf("%s: damage = %d, want %d", tt.name, dmg, tt.wantDmg)
+		}
+		if target.HasEffect(EffectBurning) != tt.wantEffect {
+			t.Errorf("%s: burning = %v, want %v", tt.name,
+				target.HasEffect(EffectBurning), tt.wantEffect)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	/* Attacker stands on 4, 5, and shot hits creature on 5, 5;
+	   chain may jump to creatures "a" and "b". Attacker is
+	   adjacent to hit creature, but it is never chained. */
+	var tests = []struct {
+		name   string
+		length int
+		ax, ay int
+		aHP    int
+		bx, by int
+		wantA  int
+		wantB  int
+	}{
+		{"two jumps", 2, 6, 5, 10, 7, 5, 8, 9},
+		{"diagonal jumps", 2, 6, 6, 10, 7, 7, 8, 9},
+		{"limited by length", 1, 6, 5, 10, 7, 5, 8, 10},
+		{"not adjacent", 2, 7, 5, 10, 8, 5, 10, 10},
+		{"dead are skipped", 2, 6, 5, 0, 5, 6, 0, 8},
+		{"no jump back", 3, 6, 5, 10, 9, 9, 8, 10},
+	}
+	for _, tt := range tests {
+		c := testFighter(4, 5, 10, 4)
+		hit := testFighter(5, 5, 10, 0)
+		a := testFighter(tt.ax, tt.ay, tt.aHP, 0)
+		b := testFighter(tt.bx, tt.by, 10, 0)
+		dt := DamageType{Name: "electromagnetic", Behavior: BehaviorChain,
+			ChainLength: tt.length, ChainMultiplier: 0.5}
+		c.Chain(hit, dt, Creatures{c, hit, a, b})
+		if a.HPCurrent != tt.wantA || b.HPCurrent != tt.wantB {
+			t.Errorf("%s: HP of a, b = %d, %d; want %d, %d", tt.name,
+				a.HPCurrent, b.HPCurrent, tt.wantA, tt.wantB)
+		}
+		if c.HPCurrent != 10 || hit.HPCurrent != 10 {
+			t.Errorf("%s: attacker HP = %d, hit creature HP = %d; want 10",
+				tt.name, c.HPCurrent, hit.HPCurrent)
+		}
+	}
+}
+
+func TestElectrocute(t *testing.T) {
+	var tests = []struct {
+		tags        []string
+		wantStunned bool
+	}{
+		{[]string{TagMachine}, true},
+		{[]string{"beast", TagMachine}, true},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		c := testFighter(1, 1, 10, 0)
+		c.Tags = tt.tags
+		c.Electrocute()
+		if c.HasEffect(EffectStunned) != tt.wantStunned {
+			t.Errorf("%v: stunned = %v, want %v", tt.tags,
+				c.HasEffect(EffectStunned), tt.wantStunned)
+		}
+	}
+}
+
+func TestTraceVector(t *testing.T) {
+	/* Shot goes east from 1, 5. Creatures are passed in random
+	   order, but hits are ordered from start of vector. Wall on
+	   wallX stops the shot (0 means no additional wall). */
+	var tests = []struct {
+		name         string
+		xs           []int
+		corpse       int
+		wallX        int
+		wantHits     []int
+		wantObstacle int
+	}{
+		{"ordered hits", []int{6, 3, 9}, -1, 0, []int{3, 6, 9}, MapSizeX - 1},
+		{"stopped by wall", []int{6, 3, 9}, -1, 8, []int{3, 6}, 8},
+		{"corpses are skipped", []int{6, 3}, 1, 0, []int{6}, MapSizeX - 1},
+		{"shooter is skipped", []int{1, 4}, -1, 0, []int{4}, MapSizeX - 1},
+		{"nothing", nil, -1, 2, []int{}, 2},
+	}
+	for _, tt := range tests {
+		b := testBoard()
+		if tt.wallX > 0 {
+			b[tt.wallX][5].Blocked = true
+		}
+		cs := Creatures{}
+		for i, x := range tt.xs {
+			c := testFighter(x, 5, 1, 0)
+			c.Blocked = i != tt.corpse
+			cs = append(cs, c)
+		}
+		vec := FireVector(1, 5, 1, 0, MapSizeX)
+		TraceVector(vec, b, cs)
+		var hits = []int{}
+		for _, v := range vec.Hits {
+			hits = append(hits, v.X)
+		}
+		if reflect.DeepEqual(hits, tt.wantHits) == false {
+			t.Errorf("%s: hits on %v, want %v", tt.name, hits, tt.wantHits)
+		}
+		if vec.Obstacle == nil || vec.Obstacle.X != tt.wantObstacle {
+			t.Errorf("%s: obstacle = %v, want tile on %d, 5", tt.name,
+				vec.Obstacle, tt.wantObstacle)
+		}
+	}
+}
+
+func TestPierce(t *testing.T) {
+	/* Every target has 10 HP; attacker's attack is 4. */
+	var tests = []struct {
+		name    string
+		pierce  int
+		falloff float64
+		wantHP  []int
+	}{
+		{"no pierce", 0, 0.5, []int{6, 10, 10}},
+		{"pierce one", 1, 0.5, []int{6, 8, 10}},
+		{"pierce all", 2, 0.5, []int{6, 8, 9}},
+		{"falloff keeps at least 1", 5, 0.1, []int{6, 9, 9}},
+		{"no falloff", 2, 1.0, []int{6, 6, 6}},
+	}
+	for _, tt := range tests {
+		c := testFighter(1, 1, 10, 4)
+		hits := Creatures{testFighter(2, 1, 10, 0), testFighter(3, 1, 10, 0),
+			testFighter(4, 1, 10, 0)}
+		dt := DamageType{Name: "ballistic", Behavior: BehaviorPierce,
+			Pierce: tt.pierce, PierceFalloff: tt.falloff}
+		c.Pierce(hits, dt)
+		var hp = []int{}
+		for _, v := range hits {
+			hp = append(hp, v.HPCurrent)
+		}
+		if reflect.DeepEqual(hp, tt.wantHP) == false {
+			t.Errorf("%s: HP = %v, want %v", tt.name, hp, tt.wantHP)
+		}
+	}
+}
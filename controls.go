@@ -27,6 +27,10 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+
 	blt "bearlibterminal"
 )
 
@@ -44,10 +48,21 @@ const (
 	StrAttackEast  = "ATTACK_EAST"
 	StrAttackSouth = "ATTACK_SOUTH"
 	StrPickup      = "PICKUP"
+	StrSetWeapon   = "CHOOSE_WEAPON_"
 	StrSetWeapon1  = "CHOOSE_WEAPON_1"
 	StrSetWeapon2  = "CHOOSE_WEAPON_2"
 	StrSetWeapon3  = "CHOOSE_WEAPON_3"
 	StrSetWeapon4  = "CHOOSE_WEAPON_4"
+	StrNextWeapon  = "CHOOSE_WEAPON_NEXT"
+	StrWait        = "WAIT"
+	StrRest        = "REST"
+	StrConvertAmmo = "CONVERT_AMMO"
+	StrUseItem1    = "USE_ITEM_1"
+	StrUseItem2    = "USE_ITEM_2"
+	StrUseItem3    = "USE_ITEM_3"
+	StrDrop        = "DROP"
+	StrMessageLog  = "MESSAGE_LOG"
+	StrLook        = "LOOK"
 )
 
 var Actions = []string{
@@ -65,30 +80,96 @@ var Actions = []string{
 	StrSetWeapon2,
 	StrSetWeapon3,
 	StrSetWeapon4,
+	StrNextWeapon,
+	StrWait,
+	StrRest,
+	StrConvertAmmo,
+	StrUseItem1,
+	StrUseItem2,
+	StrUseItem3,
+	StrDrop,
+	StrMessageLog,
+	StrLook,
 }
 
 var CommandKeys = map[int]string{
 	// Mapping keyboard scancodes to Action identifiers.
-	blt.TK_W:     StrMoveNorth,
-	blt.TK_D:     StrMoveEast,
-	blt.TK_S:     StrMoveSouth,
-	blt.TK_A:     StrMoveWest,
-	blt.TK_UP:    StrAttackNorth,
-	blt.TK_RIGHT: StrAttackEast,
-	blt.TK_DOWN:  StrAttackSouth,
-	blt.TK_LEFT:  StrAttackWest,
-	blt.TK_SPACE: StrPickup,
-	blt.TK_1:     StrSetWeapon1,
-	blt.TK_2:     StrSetWeapon2,
-	blt.TK_3:     StrSetWeapon3,
-	blt.TK_4:     StrSetWeapon4,
+	blt.TK_W:      StrMoveNorth,
+	blt.TK_D:      StrMoveEast,
+	blt.TK_S:      StrMoveSouth,
+	blt.TK_A:      StrMoveWest,
+	blt.TK_UP:     StrAttackNorth,
+	blt.TK_RIGHT:  StrAttackEast,
+	blt.TK_DOWN:   StrAttackSouth,
+	blt.TK_LEFT:   StrAttackWest,
+	blt.TK_SPACE:  StrPickup,
+	blt.TK_1:      StrSetWeapon1,
+	blt.TK_2:      StrSetWeapon2,
+	blt.TK_3:      StrSetWeapon3,
+	blt.TK_4:      StrSetWeapon4,
+	blt.TK_TAB:    StrNextWeapon,
+	blt.TK_PERIOD: StrWait,
+	blt.TK_KP_5:   StrWait,
+	blt.TK_R:      StrRest,
+	blt.TK_C:      StrConvertAmmo,
+	blt.TK_5:      StrUseItem1,
+	blt.TK_6:      StrUseItem2,
+	blt.TK_7:      StrUseItem3,
+	blt.TK_G:      StrDrop,
+	blt.TK_M:      StrMessageLog,
+	blt.TK_L:      StrLook,
+}
+
+func AddWeaponActions() {
+	/* Function AddWeaponActions adds CHOOSE_WEAPON_* actions for
+	   damage types beyond the fourth one, so every weapon defined in
+	   data file may be selected. These actions have no default keys;
+	   they may be bound in custom controls scheme. */
+	i := 0
+	for i < len(Actions) && Actions[i] != StrSetWeapon4 {
+		i++
+	}
+	var extra = []string{}
+	for n := 5; n <= len(DamageTypes); n++ {
+		extra = append(extra, StrSetWeapon+strconv.Itoa(n))
+	}
+	if i == len(Actions) || len(extra) == 0 {
+		return
+	}
+	Actions = append(Actions[:i+1], append(extra, Actions[i+1:]...)...)
 }
 
 /* Place to store customized controls scheme,
-   in the same manner as CommandKeys. */
+   in the same manner as CommandKeys. Keys may be
+   combined with modifiers (see ModShift). */
 var CustomCommandKeys = map[int]string{}
 
-func Command(com string, p *Creature, b *Board, c *Creatures) bool {
+const (
+	/* Modifier flags. They are added to scancode, so key combos
+	   (like SHIFT+S) may be stored in CustomCommandKeys as well. */
+	ModShift = 1 << 16
+	ModCtrl  = 1 << 17
+	ModAlt   = 1 << 18
+	ModMask  = ModShift | ModCtrl | ModAlt
+)
+
+// Names of modifiers, in order used in key names.
+var ModifierNames = []string{"SHIFT", "CTRL", "ALT"}
+
+var Modifiers = map[string]int{
+	"SHIFT": ModShift,
+	"CTRL":  ModCtrl,
+	"ALT":   ModAlt,
+}
+
+/* ReservedKeys are combos handled before controls scheme
+   (see main.go); binding them to actions has no effect. */
+var ReservedKeys = map[int]string{
+	ModShift | blt.TK_S: "save and quit",
+	ModShift | blt.TK_Q: "abandon run",
+}
+
+func Command(com string, p *Creature, b *Board, o *Objects, c *Creatures) bool {
 	/* Function Command handles input received from Controls.
 	   Most important argument passed to Command is string "com" that
 	   is action identifier (action identifiers are stored as constants
@@ -97,6 +178,11 @@ func Command(com string, p *Creature, b *Board, c *Creatures) bool {
 	   Returns true if command is valid and takes turn.
 	   Otherwise, return false. */
 	turnSpent := false
+	if com != StrAttackNorth && com != StrAttackEast &&
+		com != StrAttackSouth && com != StrAttackWest {
+		// Any other action cancels targeting.
+		Targeting.Active = false
+	}
 	switch com {
 	case StrMoveNorth:
 		turnSpent = p.MoveOrAttack(0, -1, *b, *c)
@@ -107,49 +193,158 @@ func Command(com string, p *Creature, b *Board, c *Creatures) bool {
 	case StrMoveWest:
 		turnSpent = p.MoveOrAttack(-1, 0, *b, *c)
 	case StrAttackNorth:
-		turnSpent = p.Shoot(0, -1, *b, *c)
+		turnSpent = p.Aim(0, -1, *b, *c)
 	case StrAttackEast:
-		turnSpent = p.Shoot(1, 0, *b, *c)
+		turnSpent = p.Aim(1, 0, *b, *c)
 	case StrAttackSouth:
-		turnSpent = p.Shoot(0, 1, *b, *c)
+		turnSpent = p.Aim(0, 1, *b, *c)
 	case StrAttackWest:
-		turnSpent = p.Shoot(-1, 0, *b, *c)
+		turnSpent = p.Aim(-1, 0, *b, *c)
 	case StrPickup:
-		turnSpent = p.PickUp(*b)
-	case StrSetWeapon1:
-		turnSpent = p.SetWeapon(1)
-	case StrSetWeapon2:
-		turnSpent = p.SetWeapon(2)
-	case StrSetWeapon3:
-		turnSpent = p.SetWeapon(3)
-	case StrSetWeapon4:
-		turnSpent = p.SetWeapon(4)
+		turnSpent = p.PickUp(*b, o)
+	case StrNextWeapon:
+		turnSpent = p.SetWeapon((p.Active+1)%len(DamageTypes) + 1)
+	case StrWait:
+		turnSpent = true
+	case StrRest:
+		turnSpent = p.StartRest(*b, *c)
+	case StrConvertAmmo:
+		turnSpent = p.ConvertAmmo()
+	case StrUseItem1:
+		turnSpent = p.UseItem(1, *b, o, *c)
+	case StrUseItem2:
+		turnSpent = p.UseItem(2, *b, o, *c)
+	case StrUseItem3:
+		turnSpent = p.UseItem(3, *b, o, *c)
+	case StrDrop:
+		turnSpent = p.ChooseItemToDrop(*b, o, *c)
+	case StrMessageLog:
+		LogScreen()
+	case StrLook:
+		Look(*b, *o, *c)
+	default:
+		if strings.HasPrefix(com, StrSetWeapon) == true {
+			n, err := strconv.Atoi(strings.TrimPrefix(com, StrSetWeapon))
+			if err == nil {
+				turnSpent = p.SetWeapon(n)
+			}
+		}
 	}
 	return turnSpent
 }
 
-func Controls(k int, p *Creature, b *Board, c *Creatures) bool {
+func Controls(k int, p *Creature, b *Board, o *Objects, c *Creatures) bool {
 	/* Function Controls takes integer 'k' (that is pressed key - blt uses
 	   scancodes internally) and trying to find match key-command in
 	   CommandKeys.
-	   Value to return is determined in Command func. */
+	   Value to return is determined in Command func.
+	   Highlight of recently healed HP lasts until the next input. */
+	LastHealed = 0
+	AgeMessages()
 	turnSpent := false
-	var command string
+	command := KeyToCommand(KeyWithModifiers(k))
+	turnSpent = Command(command, p, b, o, c)
+	return turnSpent
+}
+
+func KeyNames(action string) string {
+	/* Function KeyNames returns names of all keys bound to action
+	   in the current controls scheme, separated by commas. */
+	if CustomControls == true {
+		return keyNamesIn(CustomCommandKeys, action)
+	}
+	return keyNamesIn(CommandKeys, action)
+}
+
+func CustomKeyNames(action string) string {
+	/* Function CustomKeyNames works like KeyNames, but always uses
+	   custom controls scheme; it is used to edit and save bindings. */
+	return keyNamesIn(CustomCommandKeys, action)
+}
+
+func keyNamesIn(keys map[int]string, action string) string {
+	var codes = []int{}
+	for k, v := range keys {
+		if v == action {
+			codes = append(codes, k)
+		}
+	}
+	sort.Ints(codes)
+	var names = []string{}
+	for _, v := range codes {
+		names = append(names, KeyName(v))
+	}
+	return strings.Join(names, ", ")
+}
+
+/* KeyGlyphs are characters used as names of keys: capital letters,
+   digits, and symbols typed without Shift on QWERTY keyboard. */
+const KeyGlyphs = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789,./;'[]-="
+
+func KeyName(code int) string {
+	/* Function KeyName returns name of key, as used in
+	   options_controls.cfg. Modifiers are prefixed, like in
+	   SHIFT+S. Keys without names are returned as scancodes. */
+	prefix := ""
+	for _, v := range ModifierNames {
+		if code&Modifiers[v] != 0 {
+			prefix = prefix + v + "+"
+		}
+	}
+	code = code &^ ModMask
+	name := ""
+	for k, v := range SpecialKeys {
+		// Some keys have aliases, so the first name in order is used.
+		if v == code && (name == "" || k < name) {
+			name = k
+		}
+	}
+	if name != "" {
+		return prefix + name
+	}
+	for _, r := range KeyGlyphs {
+		if QWERTYLayoutRunesToCodes[r] == code {
+			return prefix + string(r)
+		}
+	}
+	return prefix + strconv.Itoa(code)
+}
+
+func KeyWithModifiers(k int) int {
+	/* Function KeyWithModifiers adds flags of modifiers that are
+	   held down to scancode k. */
+	if blt.Check(blt.TK_SHIFT) != 0 {
+		k = k | ModShift
+	}
+	if blt.Check(blt.TK_CONTROL) != 0 {
+		k = k | ModCtrl
+	}
+	if blt.Check(blt.TK_ALT) != 0 {
+		k = k | ModAlt
+	}
+	return k
+}
+
+func KeyToCommand(k int) string {
+	/* Function KeyToCommand returns action identifier bound to
+	   key 'k' in the current controls scheme. If key combo is
+	   not bound, modifiers are ignored - so Shift does not break
+	   movement, for example. */
 	if CustomControls == false {
-		command = CommandKeys[k]
-	} else {
-		command = CustomCommandKeys[k]
+		return CommandKeys[k&^ModMask]
 	}
-	turnSpent = Command(command, p, b, c)
-	return turnSpent
+	if com, ok := CustomCommandKeys[k]; ok == true {
+		return com
+	}
+	return CustomCommandKeys[k&^ModMask]
 }
 
 func ReadInput() int {
 	/* Function ReadInput is replacement of default blt's Read function that
 	   returns QWERTY scancode. To provide (still experimental - I don't have
 	   access to non-QWERTY keyboard physically) support for different
-	   keyboard layouts, there are maps (in options.go) that matches
-	   non-QWERTY input with QWERTY scancodes.
+	   keyboard layouts, there are maps (loaded from data/layouts, see
+	   options.go) that matches non-QWERTY input with QWERTY scancodes.
 	   Some keys are hardcoded - like numpad, enter, etc. These hardcoded
 	   keys are tested as first place as it's much cheaper operation than
 	   checking map.
@@ -166,3 +361,19 @@ func ReadInput() int {
 	}
 	return KeyMap[r]
 }
+
+const (
+	// Milliseconds between checks of input queue (see PeekClose).
+	InputDelay = 10
+)
+
+func PeekClose() bool {
+	/* Function PeekClose waits for input, and checks if it is closing
+	   the window - without reading it. Prompts opened during the game
+	   use it to leave TK_CLOSE in input queue, so game screen can
+	   save the game before quitting. */
+	for blt.HasInput() == false {
+		blt.Delay(InputDelay)
+	}
+	return blt.Peek() == blt.TK_CLOSE
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	/* Behaviors of damage types. Behavior decides what happens
+	   when shot of specific damage type hits something.
+	   Empty string means that shot just damages the first
+	   creature on its way.
+	   Explosive shots detonate at the point of impact, damaging
+	   everything within Radius and destroying walls.
+	   Knockback pushes target along the firing vector.
+	   Chain arcs from target to adjacent creatures, and stuns machines.
+	   Pierce passes through creatures, losing damage at each one. */
+	BehaviorNone      = ""
+	BehaviorExplode   = "explode"
+	BehaviorKnockback = "knockback"
+	BehaviorChain     = "chain"
+	BehaviorPierce    = "pierce"
+)
+
+type DamageType struct {
+	/* DamageType describes one type of ammunition, and damage it deals.
+	   Name is identifier used in json files (monsters' weaknesses,
+	   player's ammo, resources on map).
+	   Icon is the unicode symbol used for resources on map and in UI;
+	   ColorGood is the base color of still active source of resource;
+	   ColorBad is color of already drained - therefore inactive - resource.
+	   PickupMin and PickupMax is range of ammo obtained from
+	   one resource.
+	   Behavior is one of Behavior* constants; Radius is used
+	   by explosions only; Knockback (number of tiles) and
+	   CollisionDamage are used by knockback only; ChainLength
+	   (number of jumps) and ChainMultiplier (damage multiplier
+	   applied on every jump) are used by chain only; Pierce
+	   (number of creatures shot passes through) and PierceFalloff
+	   (damage multiplier applied after every creature) are used by
+	   pierce only.
+	   Effect is name of status effect applied to every creature
+	   damaged by this damage type; EffectDuration overrides default
+	   duration of effect if it is positive. */
+	Name            string
+	Icon            string
+	ColorGood       string
+	ColorBad        string
+	PickupMin       int
+	PickupMax       int
+	Behavior        string
+	Radius          int
+	Knockback       int
+	CollisionDamage int
+	ChainLength     int
+	ChainMultiplier float64
+	Pierce          int
+	PierceFalloff   float64
+	Effect          string
+	EffectDuration  int
+}
+
+/* DamageTypes holds all damage types, in order from data file.
+   Index of damage type is the index of weapon (see Creature.Active). */
+var DamageTypes = []DamageType{}
+
+func InitializeDamageTypes() {
+	/* Function InitializeDamageTypes reads damage types from json file
+	   at the start of the game. Errors returned by json package are
+	   not very helpful, so there is lazy panic for them - the same as
+	   in NewCreature. Other errors are only printed, as game
+	   is still playable with slightly malformed damage type -
+	   except for empty list of damage types, that is fatal.
+	   Actions for selecting weapons are added here as well. */
+	err := DamageTypesFromJson(DamageTypesPathJson, &DamageTypes)
+	if err != nil {
+		fmt.Println(err)
+		panic(-1)
+	}
+	err = ValidateDamageTypes(DamageTypes)
+	if err != nil {
+		fmt.Println(err)
+	}
+	if len(DamageTypes) == 0 {
+		// Player can not have any weapon; game is not playable.
+		panic(-1)
+	}
+	AddWeaponActions()
+}
+
+func ValidateDamageTypes(dts []DamageType) error {
+	/* Function ValidateDamageTypes checks if damage types loaded
+	   from data file are usable: names have to be unique, icons have
+	   to be one character long, pickup range has to make sense, and
+	   every damage type has to fit in UI. Returns the last error found. */
+	var err error
+	if len(dts) == 0 {
+		err = errors.New("There are no damage types defined.")
+	}
+	if len(dts) > SidebarSizeX {
+		txt := DamageTypesNumberError(len(dts), SidebarSizeX)
+		err = errors.New("Too many damage types to display." + txt)
+	}
+	for i, v := range dts {
+		if utf8.RuneCountInString(v.Icon) != 1 {
+			txt := CharacterLengthError(v.Icon)
+			err = errors.New("Damage type icon length is not equal to 1." + txt)
+		}
+		if v.PickupMin < 0 || v.PickupMin > v.PickupMax {
+			txt := PickupRangeError(v.Name, v.PickupMin, v.PickupMax)
+			err = errors.New("Damage type has invalid pickup range." + txt)
+		}
+		for _, w := range dts[:i] {
+			if v.Name == w.Name {
+				txt := DamageTypeNameError(v.Name)
+				err = errors.New("Damage type name is not unique." + txt)
+			}
+		}
+	}
+	return err
+}
+
+func DamageTypeByName(name string) (int, bool) {
+	/* Function DamageTypeByName finds damage type that matches
+	   name used in json files. Returns its index in DamageTypes,
+	   or false if there is no such damage type. */
+	for i, v := range DamageTypes {
+		if v.Name == name {
+			return i, true
+		}
+	}
+	return WrongIndexValue, false
+}
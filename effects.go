@@ -0,0 +1,220 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	/* Stacking rules - they decide what happens if effect is applied
+	   to creature that is already affected by the same effect.
+	   Refresh sets duration to the longer one;
+	   Extend adds new duration to remaining one;
+	   Intensify adds magnitudes and refreshes duration;
+	   Ignore keeps the old effect untouched. */
+	StackRefresh   = "refresh"
+	StackExtend    = "extend"
+	StackIntensify = "intensify"
+	StackIgnore    = "ignore"
+)
+
+const (
+	/* Names of effects that are handled by game mechanics.
+	   Burning deals Magnitude damage every turn;
+	   stunned creature skips its turns;
+	   slowed creature is slower (check SpeedMultiplier);
+	   shielded creature absorbs up to Magnitude damage. */
+	EffectBurning  = "burning"
+	EffectStunned  = "stunned"
+	EffectSlowed   = "slowed"
+	EffectShielded = "shielded"
+)
+
+type StatusEffect struct {
+	/* StatusEffect is temporary condition of creature.
+	   Definitions of effects are read from json file; these
+	   definitions are then copied to creatures.
+	   Icon and Color are used to display effect on map and in UI.
+	   Duration is number of turns left; Magnitude is strength
+	   of effect (its meaning depends on effect); Stacking
+	   is one of Stack* constants. SpeedMultiplier, if positive,
+	   modifies speed of creature (see scheduler.go). */
+	Name            string
+	Icon            string
+	Color           string
+	Duration        int
+	Magnitude       int
+	Stacking        string
+	SpeedMultiplier float64
+}
+
+// StatusEffects holds definitions of all effects, read from data file.
+var StatusEffects = []StatusEffect{}
+
+func InitializeStatusEffects() {
+	/* Function InitializeStatusEffects reads definitions of effects
+	   at the start of the game. As in InitializeDamageTypes, there is
+	   lazy panic for json errors, and other errors are only printed. */
+	err := StatusEffectsFromJson(StatusEffectsPathJson, &StatusEffects)
+	if err != nil {
+		fmt.Println(err)
+		panic(-1)
+	}
+	for _, v := range StatusEffects {
+		if utf8.RuneCountInString(v.Icon) != 1 {
+			txt := CharacterLengthError(v.Icon)
+			fmt.Println(errors.New("Effect icon length is not equal to 1." + txt))
+		}
+		if v.Stacking != StackRefresh && v.Stacking != StackExtend &&
+			v.Stacking != StackIntensify && v.Stacking != StackIgnore {
+			txt := StackingError(v.Name, v.Stacking)
+			fmt.Println(errors.New("Unknown stacking rule." + txt))
+		}
+	}
+}
+
+func StatusEffectByName(name string) (StatusEffect, bool) {
+	/* Function StatusEffectByName returns copy of effect definition
+	   that matches name, or false, if there is no such effect. */
+	for _, v := range StatusEffects {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return StatusEffect{}, false
+}
+
+func (c *Creature) ApplyEffect(name string, duration int) {
+	/* ApplyEffect is method that puts effect on receiver.
+	   If duration is not positive, default duration of effect
+	   is used. If receiver is affected already, effect
+	   stacks according to its Stacking rule.
+	   Dead creatures can not be affected. */
+	if c.HPCurrent <= 0 {
+		return
+	}
+	effect, ok := StatusEffectByName(name)
+	if ok == false {
+		txt := StatusEffectNameError(name)
+		fmt.Println(errors.New("Unknown status effect." + txt))
+		return
+	}
+	if duration > 0 {
+		effect.Duration = duration
+	}
+	for i, v := range c.Effects {
+		if v.Name != name {
+			continue
+		}
+		switch v.Stacking {
+		case StackRefresh:
+			if effect.Duration > v.Duration {
+				c.Effects[i].Duration = effect.Duration
+			}
+		case StackExtend:
+			c.Effects[i].Duration += effect.Duration
+		case StackIntensify:
+			c.Effects[i].Magnitude += effect.Magnitude
+			if effect.Duration > v.Duration {
+				c.Effects[i].Duration = effect.Duration
+			}
+		}
+		return
+	}
+	c.Effects = append(c.Effects, effect)
+}
+
+func (c *Creature) HasEffect(name string) bool {
+	/* HasEffect returns true if receiver is affected by
+	   effect passed as argument. */
+	for _, v := range c.Effects {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Creature) RemoveEffect(name string) {
+	/* RemoveEffect removes effect from receiver immediately. */
+	var effects = []StatusEffect{}
+	for _, v := range c.Effects {
+		if v.Name != name {
+			effects = append(effects, v)
+		}
+	}
+	c.Effects = effects
+}
+
+func (c *Creature) TickEffects() {
+	/* TickEffects is hook run at the end of every turn of receiver
+	   (player's turn in main loop, monsters' turns in
+	   CreaturesTakeTurn). It applies per-turn effects,
+	   then decreases durations and removes expired effects. */
+	if c.HPCurrent <= 0 {
+		return
+	}
+	var effects = []StatusEffect{}
+	for _, v := range c.Effects {
+		switch v.Name {
+		case EffectBurning:
+			c.TakeDamage(v.Magnitude)
+		}
+		v.Duration--
+		if v.Duration > 0 {
+			effects = append(effects, v)
+		}
+	}
+	if c.HPCurrent > 0 {
+		c.Effects = effects
+	}
+}
+
+func (c *Creature) AbsorbDamage(dmg int) int {
+	/* AbsorbDamage is used by TakeDamage. If receiver is shielded,
+	   shield absorbs as much damage as its Magnitude allows, and is
+	   weakened accordingly. Returns damage that is left. */
+	for i, v := range c.Effects {
+		if v.Name != EffectShielded || dmg <= 0 {
+			continue
+		}
+		absorbed := dmg
+		if absorbed > v.Magnitude {
+			absorbed = v.Magnitude
+		}
+		c.Effects[i].Magnitude -= absorbed
+		dmg -= absorbed
+		if c.Effects[i].Magnitude <= 0 {
+			c.RemoveEffect(EffectShielded)
+		}
+		break
+	}
+	return dmg
+}
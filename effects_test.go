@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import "testing"
+
+func TestApplyEffect(t *testing.T) {
+	defer func(old []StatusEffect) { StatusEffects = old }(StatusEffects)
+	StatusEffects = []StatusEffect{
+		{Name: "refresh", Duration: 2, Magnitude: 1, Stacking: StackRefresh},
+		{Name: "extend", Duration: 2, Magnitude: 1, Stacking: StackExtend},
+		{Name: "intensify", Duration: 2, Magnitude: 1, Stacking: StackIntensify},
+		{Name: "ignore", Duration: 2, Magnitude: 1, Stacking: StackIgnore},
+	}
+	var tests = []struct {
+		name          string
+		hp            int
+		before        []StatusEffect
+		effect        string
+		duration      int
+		wantDuration  int
+		wantMagnitude int
+	}{
+		{"new, default duration", 1, nil, "refresh", 0, 2, 1},
+		{"new, explicit duration", 1, nil, "extend", 5, 5, 1},
+		{"refresh keeps longer", 1, []StatusEffect{
+			{Name: "refresh", Duration: 4, Magnitude: 1, Stacking: StackRefresh}},
+			"refresh", 0, 4, 1},
+		{"refresh takes longer", 1, []StatusEffect{
+			{Name: "refresh", Duration: 1, Magnitude: 1, Stacking: StackRefresh}},
+			"refresh", 3, 3, 1},
+		{"extend adds", 1, []StatusEffect{
+			{Name: "extend", Duration: 3, Magnitude: 1, Stacking: StackExtend}},
+			"extend", 0, 5, 1},
+		{"intensify adds magnitude", 1, []StatusEffect{
+			{Name: "intensify", Duration: 1, Magnitude: 1, Stacking: StackIntensify}},
+			"intensify", 0, 2, 2},
+		{"ignore keeps old", 1, []StatusEffect{
+			{Name: "ignore", Duration: 1, Magnitude: 3, Stacking: StackIgnore}},
+			"ignore", 5, 1, 3},
+	}
+	for _, tt := range tests {
+		c := &Creature{}
+		c.HPCurrent = tt.hp
+		c.Effects = append([]StatusEffect{}, tt.before...)
+		c.ApplyEffect(tt.effect, tt.duration)
+		if len(c.Effects) != 1 {
+			t.Errorf("%s: %d effects, want 1", tt.name, len(c.Effects))
+			continue
+		}
+		e := c.Effects[0]
+		if e.Duration != tt.wantDuration || e.Magnitude != tt.wantMagnitude {
+			t.Errorf("%s: duration %d, magnitude %d; want %d, %d", tt.name,
+				e.Duration, e.Magnitude, tt.wantDuration, tt.wantMagnitude)
+		}
+	}
+}
+
+func TestApplyEffectIgnored(t *testing.T) {
+	/* Dead creatures, and unknown effects, are never applied. */
+	defer func(old []StatusEffect) { StatusEffects = old }(StatusEffects)
+	StatusEffects = []StatusEffect{{Name: "refresh", Duration: 2,
+		Stacking: StackRefresh}}
+	var tests = []struct {
+		hp     int
+		effect string
+	}{
+		{0, "refresh"},
+		{1, "unknown"},
+	}
+	for _, tt := range tests {
+		c := &Creature{}
+		c.HPCurrent = tt.hp
+		c.ApplyEffect(tt.effect, 0)
+		if len(c.Effects) != 0 {
+			t.Errorf("HP %d, effect %s: got %v, want no effects", tt.hp,
+				tt.effect, c.Effects)
+		}
+	}
+}
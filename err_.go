@@ -113,17 +113,133 @@ func VectorCoordinatesOutOfMapBounds(startX, startY, targetX, targetY int) strin
 	return txt
 }
 
-func CorruptedSaveError(errBoard, errCreatures error) string {
+func CorruptedSaveError(errBoard, errCreatures, errRun error) string {
 	/* Function CorruptedSaveError is helper function that returns string to error.
 	   It takes three specific errors as arguments (only one of them has to be != nil).
-	   It is called when game can not find all two save files in directory. */
-	errorBoard, errorCreatures := "", ""
+	   It is called when game can not find all three save files in directory. */
+	errorBoard, errorCreatures, errorRun := "", "", ""
 	if errBoard != nil {
 		errorBoard = "map.gob "
 	}
 	if errCreatures != nil {
 		errorCreatures = "monsters.gob "
 	}
-	txt := "\n    <Following files are missing: " + errorBoard + errorCreatures + ">"
+	if errRun != nil {
+		errorRun = "run.gob "
+	}
+	txt := "\n    <Following files are missing: " + errorBoard + errorCreatures +
+		errorRun + ">"
+	return txt
+}
+
+func SpawnRangeError(level, min, max int) string {
+	/* Function SpawnRangeError is helper function that returns string to error.
+	   It takes level index and monsters range of this level as arguments.
+	   MonstersMin should not be negative, nor greater than MonstersMax. */
+	txt := "\n    <level: " + strconv.Itoa(level+1) + "; MonstersMin: " +
+		strconv.Itoa(min) + "; MonstersMax: " + strconv.Itoa(max) + ">"
+	return txt
+}
+
+func SpawnEntryError(level int, monster string, weight, threat int) string {
+	/* Function SpawnEntryError is helper function that returns string to error.
+	   It is called when spawn table entry has non-positive weight
+	   or negative threat. */
+	txt := "\n    <level: " + strconv.Itoa(level+1) + "; monster: " + monster +
+		"; weight: " + strconv.Itoa(weight) + "; threat: " +
+		strconv.Itoa(threat) + ">"
+	return txt
+}
+
+func DamageTypeNameError(name string) string {
+	/* Function DamageTypeNameError is helper function that returns string
+	   to error; it takes name of damage type, as written in json file.
+	   It is called if there is no damage type that matches that name. */
+	txt := "\n    <damage type: " + name + ">"
+	return txt
+}
+
+func DamageTypesNumberError(number, max int) string {
+	/* Function DamageTypesNumberError is helper function that returns string
+	   to error; it takes number of loaded damage types, and number of
+	   damage types that UI is able to display. */
+	txt := "\n    <damage types: " + strconv.Itoa(number) +
+		"; max: " + strconv.Itoa(max) + ">"
+	return txt
+}
+
+func PickupRangeError(name string, min, max int) string {
+	/* Function PickupRangeError is helper function that returns string
+	   to error; it takes name of damage type, and range of ammo
+	   obtained from resource. */
+	txt := "\n    <damage type: " + name + "; PickupMin: " + strconv.Itoa(min) +
+		"; PickupMax: " + strconv.Itoa(max) + ">"
+	return txt
+}
+
+func StackingError(name, stacking string) string {
+	/* Function StackingError is helper function that returns string
+	   to error; it takes name of effect and its stacking rule. */
+	txt := "\n    <effect: " + name + "; stacking: " + stacking + ">"
+	return txt
+}
+
+func StatusEffectNameError(name string) string {
+	/* Function StatusEffectNameError is helper function that returns string
+	   to error; it is called if there is no effect that matches name. */
+	txt := "\n    <effect: " + name + ">"
+	return txt
+}
+
+func HealingValueError(value int) string {
+	/* Function HealingValueError is helper function that returns string
+	   to error; it takes wrong value read from healing json file. */
+	txt := "\n    <value: " + strconv.Itoa(value) + ">"
+	return txt
+}
+
+func HealingRangeError(min, max int) string {
+	/* Function HealingRangeError is helper function that returns string
+	   to error; it takes range of medkits per level. */
+	txt := "\n    <MedkitsMin: " + strconv.Itoa(min) +
+		"; MedkitsMax: " + strconv.Itoa(max) + ">"
+	return txt
+}
+
+func UpgradeKindError(name, kind string) string {
+	/* Function UpgradeKindError is helper function that returns string
+	   to error; it takes name of upgrade and its kind. */
+	txt := "\n    <upgrade: " + name + "; kind: " + kind + ">"
+	return txt
+}
+
+func ObjectKindError(name, kind string) string {
+	/* Function ObjectKindError is helper function that returns string
+	   to error; it takes name of object and its kind. */
+	txt := "\n    <object: " + name + "; kind: " + kind + ">"
+	return txt
+}
+
+func ObjectUseError(name, use string) string {
+	/* Function ObjectUseError is helper function that returns string
+	   to error; it takes name of consumable and its use. */
+	txt := "\n    <object: " + name + "; use: " + use + ">"
+	return txt
+}
+
+func LayoutKeyError(layout, char, key string) string {
+	/* Function LayoutKeyError is helper function that returns string
+	   to error; it takes name of keyboard layout, character, and
+	   QWERTY key it is mapped to. */
+	txt := "\n    <layout: " + layout + "; character: " + char + "; key: " + key + ">"
+	return txt
+}
+
+func RunLevelError(level, levels int) string {
+	/* Function RunLevelError is helper function that returns string
+	   to error; it takes the current level of saved run, and number
+	   of its levels. */
+	txt := "\n    <current level: " + strconv.Itoa(level) + "; levels: " +
+		strconv.Itoa(levels) + ">"
 	return txt
 }
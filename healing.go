@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+type HealingOptions struct {
+	/* HealingOptions holds all values related to restoring HP.
+	   Medkit is name of json file stored in data/objects; MedkitsMin
+	   to MedkitsMax of these objects are placed on every level,
+	   next to objects from spawn table.
+	   StairsHeal is number of HP restored on descending stairs (0 disables).
+	   ConvertCost is amount of active ammo that is converted
+	   to ConvertHeal HP by CONVERT_AMMO action. */
+	Medkit      string
+	MedkitsMin  int
+	MedkitsMax  int
+	StairsHeal  int
+	ConvertCost int
+	ConvertHeal int
+}
+
+var Healing = HealingOptions{}
+
+/* LastHealed is number of HP restored during the last player's action.
+   It is used only by PrintUI, to highlight restored HP. */
+var LastHealed = 0
+
+func InitializeHealing() {
+	/* Function InitializeHealing reads healing values at the start
+	   of the game. There is lazy panic for json errors; other errors
+	   are printed, and wrong values are set to 0. */
+	err := HealingFromJson(HealingPathJson, &Healing)
+	if err != nil {
+		fmt.Println(err)
+		panic(-1)
+	}
+	var values = []*int{&Healing.MedkitsMin, &Healing.MedkitsMax,
+		&Healing.StairsHeal, &Healing.ConvertCost, &Healing.ConvertHeal}
+	for _, v := range values {
+		if *v < 0 {
+			txt := HealingValueError(*v)
+			fmt.Println(errors.New("Healing values can not be negative." + txt))
+			*v = 0
+		}
+	}
+	if Healing.MedkitsMin > Healing.MedkitsMax {
+		txt := HealingRangeError(Healing.MedkitsMin, Healing.MedkitsMax)
+		fmt.Println(errors.New("MedkitsMin is bigger than MedkitsMax." + txt))
+		Healing.MedkitsMax = Healing.MedkitsMin
+	}
+}
+
+func (c *Creature) Heal(amount int) int {
+	/* Heal restores up to "amount" HP, but never more than HPMax.
+	   Returns number of restored HP. */
+	if c.HPCurrent <= 0 || amount <= 0 {
+		return 0
+	}
+	healed := amount
+	if c.HPCurrent+healed > c.HPMax {
+		healed = c.HPMax - c.HPCurrent
+	}
+	c.HPCurrent += healed
+	if c.AIType == PlayerAI {
+		LastHealed += healed
+	}
+	return healed
+}
+
+func (c *Creature) ConvertAmmo() bool {
+	/* ConvertAmmo trades ammo of active weapon for HP.
+	   Returns "turn spent" marker. */
+	if Healing.ConvertCost == 0 || Healing.ConvertHeal == 0 ||
+		c.Active < 0 || c.Active >= len(DamageTypes) {
+		return false
+	}
+	name := DamageTypes[c.Active].Name
+	if c.Ammo[name] < Healing.ConvertCost {
+		AddMessage("not enough "+name+" ammo to convert.", MessageColorBad)
+		return false
+	}
+	if c.HPCurrent >= c.HPMax {
+		AddMessage("you are not hurt.", MessageColorBad)
+		return false
+	}
+	c.Ammo[name] -= Healing.ConvertCost
+	AddMessage("you convert "+name+" ammo into "+
+		strconv.Itoa(c.Heal(Healing.ConvertHeal))+" HP.", MessageColorGood)
+	return true
+}
+
+func AddMedkits(level int, o *Objects) {
+	/* Adds medkits to level (counting from 0). Medkits are ordinary
+	   objects, so they can not be placed under the wall, stairs, hazards,
+	   other objects (resources included), or player. */
+	if Healing.Medkit == "" {
+		return
+	}
+	b := LevelMaps[level]
+	n := RandRange(Healing.MedkitsMin, Healing.MedkitsMax)
+	for {
+		if n == 0 {
+			break
+		}
+		x := rand.Intn(MapSizeX)
+		y := rand.Intn(MapSizeY)
+		if IsLevelEntrance(level, x, y) == true {
+			continue
+		}
+		if b[x][y].Blocked == true ||
+			b[x][y].Stairs == true ||
+			b[x][y].Hazard == true ||
+			FindObjectByXY(x, y, *o) != nil {
+			continue
+		}
+		medkit, err := NewObject(x, y, Healing.Medkit)
+		if err != nil {
+			fmt.Println(err)
+		}
+		*o = append(*o, medkit)
+		n--
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"math/rand"
+
+	blt "bearlibterminal"
+)
+
+// Number of inventory slots of player.
+const InventorySlots = 3
+
+const (
+	/* Effects of consumables, as written in Use field of json file.
+	   Teleport moves user to random free tile;
+	   shield puts shielded effect on user (Amount is duration, if positive);
+	   grenade explodes on the nearest monster in view, within Amount tiles,
+	   dealing damage of DamageType - monsters behind other creatures or
+	   walls, and monsters so close that blast would reach user, are skipped;
+	   converter moves Amount ammo from the most plentiful other damage
+	   type to the active weapon. */
+	UseTeleport  = "teleport"
+	UseShield    = "shield"
+	UseGrenade   = "grenade"
+	UseConverter = "converter"
+)
+
+func (c *Creature) AddToInventory(object *Object) bool {
+	/* AddToInventory puts object to the first free slot of inventory.
+	   Returns false if inventory is full. */
+	if len(c.Inventory) >= InventorySlots {
+		AddMessage("your inventory is full.", MessageColorBad)
+		return false
+	}
+	c.Inventory = append(c.Inventory, object)
+	return true
+}
+
+func (c *Creature) UseItem(i int, b Board, o *Objects, cs Creatures) bool {
+	/* UseItem takes slot number (1-based, as SetWeapon) and uses item from
+	   this slot. Used items are removed from inventory. Returns
+	   "turn spent" marker; items that would have no effect are not used. */
+	i--
+	if i < 0 || i >= len(c.Inventory) {
+		AddMessage("this slot is empty.", MessageColorBad)
+		return false
+	}
+	item := c.Inventory[i]
+	used := false
+	switch item.Use {
+	case UseTeleport:
+		used = c.Teleport(b, cs)
+	case UseShield:
+		c.ApplyEffect(EffectShielded, item.Amount)
+		used = true
+	case UseGrenade:
+		used = c.ThrowGrenade(item, b, cs)
+	case UseConverter:
+		used = c.ConvertAmmoFromOther(item.Amount)
+	}
+	if used == true {
+		AddMessage("you use "+item.Name+".", MessageColorGood)
+		c.Inventory = append(c.Inventory[:i], c.Inventory[i+1:]...)
+	} else {
+		AddMessage(item.Name+" would have no effect now.", MessageColorBad)
+	}
+	return used
+}
+
+func (c *Creature) ChooseItemToDrop(b Board, o *Objects, cs Creatures) bool {
+	/* ChooseItemToDrop is prompt opened by DROP action. It reads
+	   the next key: USE_ITEM_n keys (or slot number) drop item from
+	   that slot, and any other key cancels dropping. Closing window is
+	   left in input queue, for game screen (see PeekClose).
+	   Returns "turn spent" marker. */
+	if len(c.Inventory) == 0 {
+		AddMessage("you have nothing to drop.", MessageColorBad)
+		return false
+	}
+	AddMessage("drop which item? (Esc cancels)", MessageColorInfo)
+	RenderAll(b, *o, cs)
+	if PeekClose() == true {
+		return false
+	}
+	key := ReadInput()
+	slot := 0
+	switch KeyToCommand(KeyWithModifiers(key)) {
+	case StrUseItem1:
+		slot = 1
+	case StrUseItem2:
+		slot = 2
+	case StrUseItem3:
+		slot = 3
+	}
+	if key >= blt.TK_1 && key < blt.TK_1+InventorySlots {
+		slot = key - blt.TK_1 + 1
+	}
+	if slot == 0 {
+		return false
+	}
+	if slot > len(c.Inventory) {
+		AddMessage("this slot is empty.", MessageColorBad)
+		return false
+	}
+	return c.DropItem(slot-1, o)
+}
+
+func (c *Creature) DropItem(i int, o *Objects) bool {
+	/* DropItem puts item from inventory slot (0-based) on the tile
+	   under receiver. Only one object may lie on the tile. */
+	if FindObjectByXY(c.X, c.Y, *o) != nil {
+		AddMessage("there is no room to drop it here.", MessageColorBad)
+		return false
+	}
+	item := c.Inventory[i]
+	AddMessage("you drop "+item.Name+".", MessageColorInfo)
+	item.X, item.Y = c.X, c.Y
+	*o = append(*o, item)
+	c.Inventory = append(c.Inventory[:i], c.Inventory[i+1:]...)
+	return true
+}
+
+func (c *Creature) Teleport(b Board, cs Creatures) bool {
+	/* Teleport moves receiver to random passable tile that is not
+	   hazard, stairs, nor occupied by other creature. */
+	var xs, ys = []int{}, []int{}
+	for x := 0; x < MapSizeX; x++ {
+		for y := 0; y < MapSizeY; y++ {
+			t := b[x][y]
+			if t.Blocked == true || t.Hazard == true || t.Stairs == true ||
+				GetAliveCreatureFromTile(x, y, cs) != nil {
+				continue
+			}
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	if len(xs) == 0 {
+		return false
+	}
+	n := rand.Intn(len(xs))
+	c.X, c.Y = xs[n], ys[n]
+	return true
+}
+
+func (c *Creature) ThrowGrenade(item *Object, b Board, cs Creatures) bool {
+	/* ThrowGrenade detonates item on the nearest monster that is in
+	   receiver's view, within item.Amount tiles. Grenade needs clear
+	   line - it would hit anything else on the way - and target
+	   needs to be out of blast radius, so user is never caught in
+	   own explosion. Returns false if there is no such monster. */
+	dt, ok := DamageTypeByName(item.DamageType)
+	if ok == false {
+		return false
+	}
+	var target *Creature
+	for _, v := range cs {
+		if v == c || v.HPCurrent <= 0 || v.AIType == NoAI {
+			continue
+		}
+		distance := c.DistanceTo(v.X, v.Y)
+		if distance > item.Amount || distance <= DamageTypes[dt].Radius ||
+			c.CanSee(v.X, v.Y, b) == false {
+			continue
+		}
+		vec, err := NewVector(c.X, c.Y, v.X, v.Y)
+		if err != nil {
+			continue
+		}
+		ComputeVector(vec)
+		TraceVector(vec, b, cs)
+		if vec.Obstacle != nil || len(vec.Hits) == 0 || vec.Hits[0] != v {
+			continue
+		}
+		if target == nil || distance < c.DistanceTo(target.X, target.Y) {
+			target = v
+		}
+	}
+	if target == nil {
+		return false
+	}
+	c.Explode(target.X, target.Y, DamageTypes[dt], b, cs)
+	return true
+}
+
+func (c *Creature) ConvertAmmoFromOther(amount int) bool {
+	/* ConvertAmmoFromOther moves up to "amount" ammo from the most
+	   plentiful damage type (other than active one) to the active weapon. */
+	if c.Active < 0 || c.Active >= len(DamageTypes) || c.Ammo == nil {
+		return false
+	}
+	active := DamageTypes[c.Active].Name
+	source := ""
+	for _, v := range DamageTypes {
+		if v.Name == active {
+			continue
+		}
+		if source == "" || c.Ammo[v.Name] > c.Ammo[source] {
+			source = v.Name
+		}
+	}
+	if source == "" || c.Ammo[source] == 0 || c.Ammo[active] >= c.AmmoCap(active) {
+		return false
+	}
+	for n := 0; n < amount; n++ {
+		if c.Ammo[source] == 0 || c.Ammo[active] >= c.AmmoCap(active) {
+			break
+		}
+		c.Ammo[source]--
+		c.Ammo[active]++
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	blt "bearlibterminal"
+)
+
+const (
+	// Message log values. Only LogSize newest messages are kept.
+	LogSize          = 200
+	MessageColorInfo = "light gray"
+	MessageColorGood = "light green"
+	MessageColorBad  = "light red"
+	MessageColorOld  = "gray"
+)
+
+type Message struct {
+	/* Message is single entry of message log. Count is number of
+	   identical messages in a row; Fresh marks messages added since
+	   the last player's input. */
+	Text  string
+	Color string
+	Count int
+	Fresh bool
+}
+
+var MessageLog = []Message{}
+
+func AddMessage(text, color string) {
+	/* Function AddMessage puts new message to the log. The first
+	   letter is capitalized, and repeated messages are merged. */
+	if text == "" {
+		return
+	}
+	r, size := utf8.DecodeRuneInString(text)
+	text = string(unicode.ToUpper(r)) + text[size:]
+	last := len(MessageLog) - 1
+	if last >= 0 && MessageLog[last].Text == text && MessageLog[last].Fresh == true {
+		MessageLog[last].Count++
+		return
+	}
+	MessageLog = append(MessageLog, Message{text, color, 1, true})
+	if len(MessageLog) > LogSize {
+		MessageLog = MessageLog[len(MessageLog)-LogSize:]
+	}
+}
+
+func AgeMessages() {
+	/* Function AgeMessages marks all messages as old. It is called
+	   on every player input, so fresh messages are the ones
+	   from the last turn. */
+	for i := range MessageLog {
+		MessageLog[i].Fresh = false
+	}
+}
+
+func (c *Creature) LogName() string {
+	/* LogName returns name of creature as used in messages. */
+	if c.AIType == PlayerAI {
+		return "you"
+	}
+	return c.Name
+}
+
+func (m Message) String() string {
+	/* String returns text of message, with counter of repeats. */
+	if m.Count > 1 {
+		return m.Text + " (x" + strconv.Itoa(m.Count) + ")"
+	}
+	return m.Text
+}
+
+func WrapText(s string, width int) []string {
+	/* Function WrapText splits text into lines that are not longer than
+	   width. Words longer than width are cut. It does not handle
+	   BearLibTerminal markup, so it should be used on plain text. */
+	var lines = []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			r := []rune(word)
+			lines = append(lines, string(r[:width]))
+			word = string(r[width:])
+		}
+		if line == "" {
+			line = word
+		} else if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width {
+			line = line + " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func logLines(width int) ([]string, []string) {
+	/* Function logLines wraps whole message log to given width.
+	   Returns lines and their colors. */
+	var lines, colors = []string{}, []string{}
+	for _, m := range MessageLog {
+		color := m.Color
+		if m.Fresh == false {
+			color = MessageColorOld
+		}
+		for _, v := range WrapText(m.String(), width) {
+			lines = append(lines, v)
+			colors = append(colors, color)
+		}
+	}
+	return lines, colors
+}
+
+func PrintLog() {
+	/* Function PrintLog prints the newest messages in the strip
+	   under the UI. */
+	blt.Layer(UILayer)
+	lines, colors := logLines(LogSizeX)
+	start := len(lines) - LogSizeY
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(lines); i++ {
+		blt.Print(LogPosX, LogPosY+i-start,
+			"[color="+colors[i]+"]"+EscapeBltString(lines[i])+"[/color]")
+	}
+}
+
+func LogScreen() {
+	/* Function LogScreen shows the whole message history. It may be
+	   scrolled with arrows, page up / page down, home / end, and
+	   closed with Escape. Browsing history never takes a turn. */
+	pane := &TextPane{X: 0, Y: 0, W: WindowSizeX, H: WindowSizeY - 1}
+	pane.Lines, pane.Colors = logLines(pane.W)
+	pane.ScrollToEnd()
+	for {
+		blt.Clear()
+		pane.Draw()
+		PrintHint(WindowSizeY-1, "Esc: close")
+		blt.Refresh()
+		if PeekClose() == true {
+			return
+		}
+		key := ReadInput()
+		if key == blt.TK_ESCAPE {
+			return
+		}
+		pane.HandleKey(key)
+	}
+}
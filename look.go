@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	blt "bearlibterminal"
+)
+
+func Look(b Board, o Objects, c Creatures) {
+	/* Function Look is cursor mode for examining map. Cursor starts
+	   on player, and is moved with movement (or attack) keys;
+	   description of everything under cursor is printed in place
+	   of UI and message log. Escape (or LOOK key) closes
+	   look mode; closing window is left in input queue, so game screen
+	   saves the game (see PeekClose). Looking never takes a turn. */
+	x, y := c[0].X, c[0].Y
+	for {
+		RenderAll(b, o, c)
+		PrintRangedCharacter(x, y, VectorColorNeutral, true)
+		PrintDescription(Describe(x, y, b, o, c))
+		blt.Refresh()
+		if PeekClose() == true {
+			return
+		}
+		key := ReadInput()
+		if key == blt.TK_ESCAPE || KeyToCommand(key) == StrLook {
+			return
+		}
+		dx, dy := CursorDirection(key)
+		if x+dx >= 0 && x+dx < MapSizeX && y+dy >= 0 && y+dy < MapSizeY {
+			x, y = x+dx, y+dy
+		}
+	}
+}
+
+func CursorDirection(key int) (int, int) {
+	/* Function CursorDirection translates key into direction of cursor
+	   movement. Both movement and attack actions move cursor;
+	   numpad works regardless of controls scheme. */
+	switch KeyToCommand(key) {
+	case StrMoveNorth, StrAttackNorth:
+		return 0, -1
+	case StrMoveEast, StrAttackEast:
+		return 1, 0
+	case StrMoveSouth, StrAttackSouth:
+		return 0, 1
+	case StrMoveWest, StrAttackWest:
+		return -1, 0
+	}
+	switch key {
+	case blt.TK_KP_8:
+		return 0, -1
+	case blt.TK_KP_6:
+		return 1, 0
+	case blt.TK_KP_2:
+		return 0, 1
+	case blt.TK_KP_4:
+		return -1, 0
+	case blt.TK_KP_7:
+		return -1, -1
+	case blt.TK_KP_9:
+		return 1, -1
+	case blt.TK_KP_1:
+		return -1, 1
+	case blt.TK_KP_3:
+		return 1, 1
+	}
+	return 0, 0
+}
+
+func Describe(x, y int, b Board, o Objects, c Creatures) string {
+	/* Function Describe returns description of tile at x, y:
+	   creature standing there (name, HP, affinities, effects),
+	   object lying there (resources included), and the tile
+	   itself (hazards, stairs). */
+	var parts = []string{}
+	if t := GetAliveCreatureFromTile(x, y, c); t != nil {
+		parts = append(parts, t.DescribeCreature())
+	} else if t := FindMonsterByXY(x, y, c); t != nil {
+		parts = append(parts, t.Name+".")
+	}
+	if object := FindObjectByXY(x, y, o); object != nil {
+		parts = append(parts, "Item: "+object.Name+".")
+	}
+	parts = append(parts, b[x][y].Describe())
+	return strings.Join(parts, " ")
+}
+
+func (c *Creature) DescribeCreature() string {
+	/* DescribeCreature returns name, HP, affinities and status
+	   effects of receiver, in the order of DamageTypes. */
+	txt := c.Name + " (HP " + strconv.Itoa(c.HPCurrent) + "/" +
+		strconv.Itoa(c.HPMax) + ")."
+	var lists = map[int][]string{}
+	for _, v := range DamageTypes {
+		if a, ok := c.Affinities[v.Name]; ok == true {
+			lists[a] = append(lists[a], v.Name)
+		}
+	}
+	var labels = []string{"Weak:", "Resists:", "Immune:"}
+	var affinities = []int{AffinityWeak, AffinityResistant, AffinityImmune}
+	for i, a := range affinities {
+		if len(lists[a]) > 0 {
+			txt = txt + " " + labels[i] + " " + strings.Join(lists[a], ", ") + "."
+		}
+	}
+	if len(c.Effects) > 0 {
+		var effects = []string{}
+		for _, v := range c.Effects {
+			effects = append(effects, v.Name)
+		}
+		txt = txt + " Effects: " + strings.Join(effects, ", ") + "."
+	}
+	return txt
+}
+
+func (t *Tile) Describe() string {
+	/* Describe returns name of tile, and its special features. */
+	txt := t.Name
+	if t.Stairs == true {
+		txt = "stairs down"
+	}
+	return "Tile: " + txt + "."
+}
+
+func PrintDescription(txt string) {
+	/* Function PrintDescription prints text in place of UI and message
+	   log. Lines that do not fit are cut. */
+	blt.Layer(UILayer)
+	blt.ClearArea(UIPosX, UIPosY, UISizeX, UISizeY+LogSizeY)
+	for i, v := range WrapText(txt, UISizeX) {
+		if i >= UISizeY+LogSizeY {
+			break
+		}
+		blt.Print(UIPosX, UIPosY+i, "[color="+VectorColorNeutral+"]"+
+			EscapeBltString(v)+"[/color]")
+	}
+}
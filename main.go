@@ -28,7 +28,10 @@ package main
 
 import (
 	blt "bearlibterminal"
+	"errors"
+	"flag"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"os"
 	"strconv"
@@ -41,69 +44,220 @@ var OldLevel = 1
 var CurrentLevel = 1
 var LevelMaps = []Board{}
 var CreaturesSpawned = []Creatures{}
+var ObjectsSpawned = []Objects{}
 var GameWon = false
 
+// Seed of the current run (as typed by player), and number of kills.
+var RunSeed = ""
+var Kills = 0
+
 var KeyboardLayout int
 var CustomControls bool
+var TargetingPreview bool
 
 func main() {
-	var cells = new(Board)
-	var actors = new(Creatures)
-	StartGame(cells, actors)
-	for {
-		if OldLevel != CurrentLevel {
-			OldLevel = CurrentLevel
-			newBoard := LevelMaps[CurrentLevel-1]
-			for x := 0; x < MapSizeX; x++ {
-				for y := 0; y < MapSizeY; y++ {
-					(*cells)[x][y] = newBoard[x][y]
-				}
-			}
-			act := *actors
-			p := act[0]
-			for i, _ := range act {
-				act[i] = nil
+	/* With --check-config flag, game only validates config file
+	   (options_controls.cfg, or path passed as argument), prints
+	   problems, and exits; window is not opened then.
+	   Exit status is 1 if config has errors. */
+	checkConfig := flag.Bool("check-config", false,
+		"validate "+OptionsControlsPath+" (or file passed as argument) and exit")
+	flag.Parse()
+	if *checkConfig == true {
+		path := OptionsControlsPath
+		if flag.NArg() > 0 {
+			path = flag.Arg(0)
+		}
+		errs := ParseOptionsControls(path)
+		for _, v := range errs {
+			fmt.Println(path + ": " + v.Error())
+		}
+		n := OptionsErrorsCount(errs)
+		fmt.Println(path + ": " + strconv.Itoa(n) + " error(s), " +
+			strconv.Itoa(len(errs)-n) + " warning(s).")
+		if n > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	ReadOptionsControls()
+	ChooseKeyboardLayout()
+	InitializeBLT()
+	Screens.Push(&MenuScreen{})
+	RunScreens()
+	blt.Close()
+}
+
+type GameScreen struct {
+	/* GameScreen is the game itself: map, objects, and creatures
+	   of the current level. */
+	cells  *Board
+	objs   *Objects
+	actors *Creatures
+}
+
+func (g *GameScreen) Update() {
+	/* Update is single step of game loop: level switching, rendering,
+	   checking for death or victory, then player's (or world's) turn. */
+	cells, objs, actors := g.cells, g.objs, g.actors
+	if OldLevel != CurrentLevel {
+		OldLevel = CurrentLevel
+		AddMessage("you descend to level "+strconv.Itoa(CurrentLevel)+".",
+			MessageColorInfo)
+		PendingUpgrade = (*actors)[0].UpgradeOffer(UpgradeChoices)
+		newBoard := LevelMaps[CurrentLevel-1]
+		for x := 0; x < MapSizeX; x++ {
+			for y := 0; y < MapSizeY; y++ {
+				(*cells)[x][y] = newBoard[x][y]
 			}
-			act = nil
-			act = Creatures{p}
-			act = append(act, CreaturesSpawned[CurrentLevel-1]...)
-			*actors = act
 		}
-		RenderAll(*cells, *actors)
-		if (*actors)[0].HPCurrent <= 0 {
-			DeleteSaves()
+		act := *actors
+		p := act[0]
+		for i, _ := range act {
+			act[i] = nil
+		}
+		act = nil
+		act = Creatures{p}
+		act = append(act, CreaturesSpawned[CurrentLevel-1]...)
+		*actors = act
+		*objs = ObjectsSpawned[CurrentLevel-1]
+		PlanIntents(*cells, *actors)
+	}
+	RenderAll(*cells, *objs, *actors)
+	if (*actors)[0].HPCurrent <= 0 || GameWon == true {
+		EndGame(GameWon)
+		return
+	}
+	if len(PendingUpgrade) > 0 {
+		ChooseUpgrade((*actors)[0])
+		if len(PendingUpgrade) > 0 {
+			// Offer is left only by closing window.
 			blt.Read()
-			break
+			g.SaveAndQuit(true)
 		}
-		if GameWon == true {
-			DeleteSaves()
-			WinScreen()
-			break
+		return
+	}
+	if (*actors)[0].CanAct() == false {
+		// Player loses turn, but the world keeps moving.
+		Pause(StunnedDelay)
+		(*actors)[0].TickEffects()
+		CreaturesTakeTurn(*cells, *actors)
+		return
+	}
+	if Rest.Active == true && blt.HasInput() == true {
+		// Any key interrupts rest; the key itself is not used,
+		// unless it is closing the window.
+		if blt.Peek() != blt.TK_CLOSE {
+			blt.Read()
 		}
-		key := ReadInput()
-		if (key == blt.TK_S && blt.Check(blt.TK_SHIFT) != 0) ||
-			key == blt.TK_CLOSE {
-			err := SaveGame(*cells, *actors)
-			if err != nil {
-				fmt.Println(err)
-			}
-			break
-		} else if key == blt.TK_Q && blt.Check(blt.TK_SHIFT) != 0 {
+		Rest.Active = false
+		AddMessage("you stop resting.", MessageColorInfo)
+		return
+	}
+	if (*actors)[0].ContinueRest(*cells, *actors) == true {
+		// Resting player waits without reading input.
+		Pause(RestDelay)
+		(*actors)[0].TickEffects()
+		CreaturesTakeTurn(*cells, *actors)
+		return
+	}
+	key := ReadInput()
+	if (key == blt.TK_S && blt.Check(blt.TK_SHIFT) != 0) ||
+		key == blt.TK_CLOSE {
+		g.SaveAndQuit(key == blt.TK_CLOSE)
+	} else if key == blt.TK_Q && blt.Check(blt.TK_SHIFT) != 0 {
+		if Confirm("Abandon this run? It can not be continued later.") == true {
 			DeleteSaves()
-			break
-		} else {
-			turnSpent := Controls(key, (*actors)[0], cells, actors)
-			if turnSpent == true {
-				CreaturesTakeTurn(*cells, *actors)
-			}
+			Screens.Pop()
+		}
+	} else {
+		turnSpent := Controls(key, (*actors)[0], cells, objs, actors)
+		if turnSpent == true {
+			(*actors)[0].TickEffects()
+			CreaturesTakeTurn(*cells, *actors)
 		}
 	}
-	blt.Close()
 }
 
-func NewGame(b *Board, c *Creatures) {
+func (g *GameScreen) SaveAndQuit(closed bool) {
+	/* SaveAndQuit saves the game, then returns to main menu - or
+	   ends the game, if window is closed. */
+	err := SaveGame(*g.cells, *g.objs, *g.actors)
+	if err != nil {
+		fmt.Println(err)
+	}
+	if closed == true {
+		Screens.Clear()
+	} else {
+		Screens.Pop()
+	}
+}
+
+func EndGame(won bool) {
+	/* Function EndGame is called after death or victory. It removes
+	   saves, records score, and shows end screen that returns
+	   to main menu. */
+	DeleteSaves()
+	score := NewScore(won)
+	err := AddScore(score)
+	if err != nil {
+		fmt.Println(err)
+	}
+	Screens.Replace(&EndScreen{won, score})
+}
+
+func ResetRun(seed string) {
+	/* Function ResetRun clears all global state of the previous run,
+	   and seeds random number generator. Empty seed means random one. */
+	OldLevel = 1
+	CurrentLevel = 1
+	LevelMaps = []Board{}
+	CreaturesSpawned = []Creatures{}
+	ObjectsSpawned = []Objects{}
+	GameWon = false
+	Kills = 0
+	MessageLog = []Message{}
+	LastHealed = 0
+	PendingUpgrade = []Upgrade{}
+	Rest.Active = false
+	Targeting.Active = false
+	if seed == "" {
+		seed = strconv.Itoa(rand.Intn(1000000))
+	}
+	RunSeed = seed
+	rand.Seed(StringToSeed(seed))
+	TerminalSeed = "(" + seed + ")"
+	SetWindowTitle()
+}
+
+func StartNewGame(seed string) *GameScreen {
+	/* Function StartNewGame removes old saves, and creates
+	   screen with brand new game. */
+	DeleteSaves()
+	ResetRun(seed)
+	g := &GameScreen{new(Board), new(Objects), new(Creatures)}
+	NewGame(g.cells, g.objs, g.actors)
+	return g
+}
+
+func ContinueGame() (*GameScreen, error) {
+	/* Function ContinueGame creates screen with saved game.
+	   Seed is restored by LoadGame. Save that can not be loaded
+	   is corrupted - error is returned, and game must not start. */
+	ResetRun("")
+	g := &GameScreen{new(Board), new(Objects), new(Creatures)}
+	err := LoadGame(g.cells, g.objs, g.actors)
+	if err != nil {
+		return nil, err
+	}
+	TerminalSeed = "(" + RunSeed + ")"
+	SetWindowTitle()
+	return g, nil
+}
+
+func NewGame(b *Board, o *Objects, c *Creatures) {
 	/* Function NewGame initializes game state - creates player, monsters,
-	   and game map. */
+	   objects, and game map. */
 	MakeLevels()
 	*b = LevelMaps[0]
 	player, err := NewPlayer(MapSizeX/2, MapSizeY/2)
@@ -111,48 +265,61 @@ func NewGame(b *Board, c *Creatures) {
 		fmt.Println(err)
 	}
 	*c = append(*c, player)
-	SpawnCreatures()
+	SpawnCreatures(Spawns)
 	*c = append(*c, CreaturesSpawned[0]...)
+	SpawnObjects(Spawns)
+	*o = ObjectsSpawned[0]
+	PlanIntents(*b, *c)
 }
 
-func StartGame(b *Board, c *Creatures) {
-	/* Function StartGame determines if game save is present (and valid), then
-	   loads data, or initializes new game.
-	   Panics if some-but-not-all save files are missing. */
+const (
+	// States of game save, as returned by CheckSave.
+	SaveMissing = iota
+	SaveValid
+	SaveCorrupted
+)
+
+func CheckSave() (int, error) {
+	/* Function CheckSave determines if game save is present (and valid).
+	   If some-but-not-all save files are missing, save is corrupted;
+	   error describes missing files then. Saves that are present,
+	   but can not be loaded, are detected by ContinueGame. */
 	_, errBoard := os.Stat(MapPathGob)
 	_, errCreatures := os.Stat(CreaturesPathGob)
-	if errBoard == nil && errCreatures == nil {
-		LoadGame(b, c)
-	} else if errBoard != nil && errCreatures != nil {
-		NewGame(b, c)
-	} else {
-		txt := CorruptedSaveError(errBoard, errCreatures)
-		fmt.Println("Error: save files are corrupted: " + txt)
-		panic(-1)
+	_, errRun := os.Stat(RunPathGob)
+	if errBoard == nil && errCreatures == nil && errRun == nil {
+		return SaveValid, nil
+	} else if errBoard != nil && errCreatures != nil && errRun != nil {
+		return SaveMissing, nil
 	}
+	txt := CorruptedSaveError(errBoard, errCreatures, errRun)
+	return SaveCorrupted, errors.New("Save files are corrupted." + txt)
 }
 
 func StringToSeed(s string) int64 {
 	/* Function StringToSeed is important for seeded games.
-	   Now, seed is determined by time, but there are plans to
-	   allow player seed manually, even in the form of sentence.
-	   Playing Shakespeare-inspired seed? No problem! */
+	   Seed may be typed by player, even in the form of sentence -
+	   numbers are used directly, and other strings are hashed.
+	   Playing Shakespeare-inspired seed? No problem!
+	   Empty string falls back to current time. */
+	if s == "" {
+		return time.Now().UTC().UnixNano()
+	}
 	seed, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
-		fmt.Println("Error encountered during conversion from string to seed;\n" +
-			"    using current time instead.")
-		seed = time.Now().UTC().UnixNano()
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		seed = int64(h.Sum64())
 	}
 	return seed
 }
 
 func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
-	seedS := strconv.Itoa(rand.Intn(1000000))
-	rand.Seed(StringToSeed(seedS))
-	TerminalSeed = "(" + seedS + ")"
-	InitializeBLT()
+	InitializeDamageTypes()
+	InitializeSpawnTable()
+	InitializeStatusEffects()
+	InitializeHealing()
+	InitializeUpgrades()
 	InitializeKeyboardLayouts()
-	ReadOptionsControls()
-	ChooseKeyboardLayout()
 }
@@ -38,51 +38,29 @@ import (
 const (
 	// Minimum and maximum number of
 	// resources - think "ammo crates" - per level.
+	// Number of monsters is defined in spawn table (see spawn.go).
 	ResourcesMin = 3
 	ResourcesMax = 6
-	MonstersMin  = 3
-	MonstersMax  = 5
+	HazardsMin   = 1
+	HazardsMax   = 3
 )
 
 const (
-	// Iotas for resources
-	NoResource = iota
-	BallisticResource
-	ExplosiveResource
-	KineticResource
-	ElectromagneticResource
+	// Hazards are tiles that hurt every creature entering them,
+	// and slow it down.
+	HazardChar   = "^"
+	HazardColor  = "dark red"
+	HazardDamage = 1
+	HazardEffect = EffectSlowed
 )
 
-var MapResources = []int{
-	BallisticResource,
-	ExplosiveResource,
-	KineticResource,
-	ElectromagneticResource,
-}
-
-var ResourcesCharacters = map[int]string{
-	BallisticResource:       BallisticIcon,
-	ExplosiveResource:       ExplosiveIcon,
-	KineticResource:         KineticIcon,
-	ElectromagneticResource: ElectromagneticIcon,
-}
-
-var ResourcesColors = map[int][]string{
-	BallisticResource: []string{BallisticColorGood, BallisticColorBad},
-	ExplosiveResource: []string{ExplosiveColorGood, ExplosiveColorBad},
-	KineticResource:   []string{KineticColorGood, KineticColorBad},
-	ElectromagneticResource: []string{
-		ElectromagneticColorGood, ElectromagneticColorBad},
-}
-
 type Tile struct {
 	// Tiles are map cells - floors, walls, doors.
 	BasicProperties
 	VisibilityProperties
-	Explored  bool
-	Resources int
-	Drained   bool
-	Stairs    bool
+	Explored bool
+	Stairs   bool
+	Hazard   bool
 	CollisionProperties
 }
 
@@ -112,10 +90,21 @@ func NewTile(layer, x, y int, character, name, color, colorDark string,
 	tileVisibilityProperties := VisibilityProperties{layer, alwaysVisible}
 	tileCollisionProperties := CollisionProperties{blocked, blocksSight}
 	tileNew := &Tile{tileBasicProperties, tileVisibilityProperties,
-		explored, NoResource, false, false, tileCollisionProperties}
+		explored, false, false, tileCollisionProperties}
 	return tileNew, err
 }
 
+func (t *Tile) MakeFloor() {
+	/* MakeFloor is method that turns Tile into passable floor.
+	   It is used by map generator, and by explosions. */
+	t.Char = "."
+	t.Name = "floor"
+	t.Blocked = false
+	t.BlocksSight = false
+	t.Color = "light gray"
+	t.ColorDark = "dark gray"
+}
+
 func InitializeEmptyMap() Board {
 	/* Function InitializeEmptyMap returns new Board, filled with
 	   generic (ie "empty") tiles.
@@ -131,8 +120,8 @@ func InitializeEmptyMap() Board {
 	for x := 0; x < MapSizeX; x++ {
 		for y := 0; y < MapSizeY; y++ {
 			var err error
-			b[x][y], err = NewTile(BoardLayer, x, y, "#", "floor", "dark gray",
-				"darkest gray", true, true, true, false)
+			b[x][y], err = NewTile(BoardLayer, x, y, "#", "wall", "dark gray",
+				"darkest gray", true, true, true, true)
 			if err != nil {
 				fmt.Println(err)
 			}
@@ -154,10 +143,7 @@ func MakeDrunkardsMap(startX, startY int, b Board) (int, int) {
 	x, y := startX, startY
 	for {
 		if b[x][y].Blocked == true {
-			b[x][y].Char = "."
-			b[x][y].Blocked = false
-			b[x][y].Color = "light gray"
-			b[x][y].ColorDark = "dark gray"
+			b[x][y].MakeFloor()
 			diggedPercent--
 		}
 		if diggedPercent <= 0 {
@@ -230,14 +216,42 @@ func MakeNewLevel(startX, startY int) (Board, int, int) {
 	b[newX][newY].Stairs = true
 	b[newX][newY].Color = "white"
 	b[newX][newY].Char = ">"
-	AddResources(b, startX, startY)
+	AddHazards(b, startX, startY)
 	return b, newX, newY
 }
 
-func AddResources(b Board, firstX, firstY int) {
-	/* Adds resources (ammo; basically, it is just Tile with special values) to game map.
-	   Resources can not be placed under the wall, stairs, player. */
+func AddResources(level int, o *Objects) {
+	/* Adds resources (ammo deposits; see NewResource) to level
+	   (counting from 0). Resources can not be placed under the wall,
+	   stairs, hazards, other objects, or player. */
+	b := LevelMaps[level]
 	n := RandRange(ResourcesMin, ResourcesMax)
+	for {
+		if n == 0 {
+			break
+		}
+		x := rand.Intn(MapSizeX)
+		y := rand.Intn(MapSizeY)
+		if IsLevelEntrance(level, x, y) == true {
+			continue
+		}
+		if b[x][y].Blocked == true ||
+			b[x][y].Stairs == true ||
+			b[x][y].Hazard == true ||
+			FindObjectByXY(x, y, *o) != nil {
+			continue
+		}
+		resource := DamageTypes[rand.Intn(len(DamageTypes))]
+		*o = append(*o, NewResource(x, y, resource))
+		n--
+	}
+}
+
+func AddHazards(b Board, firstX, firstY int) {
+	/* Adds hazards to game map. Hazards can not be placed under the wall,
+	   stairs, player. Objects are placed later, so they avoid hazards
+	   on their own. */
+	n := RandRange(HazardsMin, HazardsMax)
 	for {
 		if n == 0 {
 			break
@@ -248,14 +262,14 @@ func AddResources(b Board, firstX, firstY int) {
 			continue
 		}
 		if b[x][y].Blocked == true ||
-			b[x][y].Resources != NoResource ||
-			b[x][y].Stairs == true {
+			b[x][y].Stairs == true ||
+			b[x][y].Hazard == true {
 			continue
 		}
-		resource := MapResources[rand.Intn(len(MapResources))]
-		b[x][y].Resources = resource
-		b[x][y].Char = ResourcesCharacters[resource]
-		b[x][y].Color = ResourcesColors[resource][0]
+		b[x][y].Hazard = true
+		b[x][y].Name = "spikes"
+		b[x][y].Char = HazardChar
+		b[x][y].Color = HazardColor
 		n--
 	}
 }
@@ -272,17 +286,25 @@ func MakeLevels() {
 	}
 }
 
-func SpawnCreatures() {
+func SpawnCreatures(table SpawnTable) {
 	/* Spawning creatures is part of generating new level for LevelMaps.
-	   Every level has own number of monsters to spawn. Enemies should not spawn
+	   Every level has own number of monsters to spawn, threat budget,
+	   and list of monsters that may appear - all of them are read from
+	   spawn table (see spawn.go). Enemies should not spawn
 	   near the player, stairs, blocked tiles (maybe over the resources as well?). */
 	for i := 0; i < NoOfLevels; i++ {
 		var cs = Creatures{}
-		n := RandRange(MonstersMin, MonstersMax)
+		spawns := table.ForLevel(i)
+		n := RandRange(spawns.MonstersMin, spawns.MonstersMax)
+		budget := spawns.ThreatBudget
 		for {
 			if n == 0 {
 				break
 			}
+			entry, ok := spawns.PickMonster(budget)
+			if ok == false {
+				break
+			}
 			x, y := rand.Intn(MapSizeX), rand.Intn(MapSizeY)
 			if i > 0 {
 				oldBoard := LevelMaps[i-1]
@@ -297,7 +319,8 @@ func SpawnCreatures() {
 					continue
 				}
 			}
-			if LevelMaps[i][x][y].Blocked == true {
+			if LevelMaps[i][x][y].Blocked == true ||
+				LevelMaps[i][x][y].Hazard == true {
 				continue
 			}
 			valid := true
@@ -309,17 +332,65 @@ func SpawnCreatures() {
 			if valid == false {
 				continue
 			}
-			newEnemy, err := NewCreature(x, y, "enemy.json")
+			newEnemy, err := NewCreature(x, y, entry.Monster)
 			if err != nil {
 				fmt.Println(err)
 			}
 			cs = append(cs, newEnemy)
+			budget -= entry.Threat
 			n--
 		}
 		CreaturesSpawned = append(CreaturesSpawned, cs)
 	}
 }
 
+func SpawnObjects(table SpawnTable) {
+	/* Objects are placed in the same manner as monsters: spawn table
+	   defines how many objects, and which ones, lie on every level.
+	   Objects are placed only on free floor - not on stairs, hazards,
+	   or other objects. Resources and medkits are placed
+	   first (see AddResources and AddMedkits). */
+	for i := 0; i < NoOfLevels; i++ {
+		var objs = Objects{}
+		AddResources(i, &objs)
+		AddMedkits(i, &objs)
+		spawns := table.ForLevel(i)
+		n := RandRange(spawns.ObjectsMin, spawns.ObjectsMax)
+		for {
+			if n == 0 {
+				break
+			}
+			entry, ok := spawns.PickObject()
+			if ok == false {
+				break
+			}
+			x, y := rand.Intn(MapSizeX), rand.Intn(MapSizeY)
+			t := LevelMaps[i][x][y]
+			if t.Blocked == true || t.Stairs == true || t.Hazard == true ||
+				FindObjectByXY(x, y, objs) != nil {
+				continue
+			}
+			newObject, err := NewObject(x, y, entry.Object)
+			if err != nil {
+				fmt.Println(err)
+			}
+			objs = append(objs, newObject)
+			n--
+		}
+		ObjectsSpawned = append(ObjectsSpawned, objs)
+	}
+}
+
+func IsLevelEntrance(level, x, y int) bool {
+	/* Function IsLevelEntrance checks if x, y is the place where
+	   player appears on level (counting from 0) - center of the first
+	   level, or tile under the stairs of previous one. */
+	if level > 0 {
+		return LevelMaps[level-1][x][y].Stairs
+	}
+	return x == MapSizeX/2 && y == MapSizeY/2
+}
+
 func MoveToNextLevel(b Board, c Creatures) {
 	/* Function MoveToNextLevel clears current level,
 	   loads the new one, spawns player and creatures, then
@@ -335,5 +406,5 @@ func MoveToNextLevel(b Board, c Creatures) {
 	p := c[0]
 	c = nil
 	c = Creatures{p}
-	RenderAll(b, c)
+	RenderAll(b, Objects{}, c)
 }
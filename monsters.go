@@ -29,7 +29,6 @@ package main
 import (
 	"errors"
 	"fmt"
-	"math/rand"
 	"unicode/utf8"
 )
 
@@ -38,6 +37,11 @@ const (
 	CorpseChar = "%"
 )
 
+const (
+	// Tags of creatures.
+	TagMachine = "machine"
+)
+
 type Creature struct {
 	/* Creatures are living objects that
 	   moves, attacks, dies, etc. */
@@ -45,6 +49,7 @@ type Creature struct {
 	VisibilityProperties
 	CollisionProperties
 	FighterProperties
+	AffinityProperties
 }
 
 // Creatures holds every creature on map.
@@ -92,19 +97,21 @@ func NewCreature(x, y int, monsterFile string) (*Creature, error) {
 		txt := InitialDefenseError(monster.Defense)
 		err2 = errors.New("Creature defense value is smaller than 0." + txt)
 	}
-	var monsterColors = []string{BallisticColorGood, KineticColorGood,
-		ElectromagneticColorGood, ExplosiveColorGood}
-	monster.Color = monsterColors[rand.Intn(len(monsterColors))]
-	monster.ColorDark = monster.Color
-	switch monster.Color {
-	case BallisticColorGood:
-		monster.Ballistic = 1
-	case KineticColorGood:
-		monster.Kinetic = 1
-	case ElectromagneticColorGood:
-		monster.Electromagnetic = 1
-	case ExplosiveColorGood:
-		monster.Explosive = 1
+	if _, ok := StatusEffectByName(monster.AttackEffect); monster.AttackEffect != "" && ok == false {
+		txt := StatusEffectNameError(monster.AttackEffect)
+		err2 = errors.New("Creature attack effect is unknown." + txt)
+	}
+	errAffinities := monster.SetAffinities()
+	if errAffinities != nil {
+		err2 = errAffinities
+	}
+	if monster.Color == "" && len(monster.Weaknesses) > 0 {
+		// Monsters without own color are tinted by their weakness.
+		dmgType, ok := DamageTypeByName(monster.Weaknesses[0])
+		if ok == true {
+			monster.Color = DamageTypes[dmgType].ColorGood
+			monster.ColorDark = monster.Color
+		}
 	}
 	return monster, err2
 }
@@ -112,25 +119,23 @@ func NewCreature(x, y int, monsterFile string) (*Creature, error) {
 func (c *Creature) Move(tx, ty int, b Board) bool {
 	/* Move is method of Creature; it takes target x, y as arguments;
 	   check if next move won't put Creature off the screen, then updates
-	   Creature coords. */
+	   Creature coords. Hazards of the new tile are resolved
+	   immediately - no matter if Creature walked there, or was pushed. */
 	turnSpent := false
 	newX, newY := c.X+tx, c.Y+ty
-	if newX >= 0 &&
-		newX <= MapSizeX-1 &&
-		newY >= 0 &&
-		newY <= MapSizeY-1 {
-		if b[newX][newY].Blocked == false {
-			c.X = newX
-			c.Y = newY
-			if b[newX][newY].Stairs == false {
-				turnSpent = true
-			} else {
-				if c.AIType == PlayerAI {
-					if CurrentLevel < len(LevelMaps) {
-						CurrentLevel++
-					} else {
-						GameWon = true
-					}
+	if c.CanMoveTo(newX, newY, b) == true {
+		c.X = newX
+		c.Y = newY
+		c.EnterTile(b)
+		if b[newX][newY].Stairs == false {
+			turnSpent = true
+		} else {
+			if c.AIType == PlayerAI {
+				if CurrentLevel < len(LevelMaps) {
+					c.Heal(Healing.StairsHeal)
+					CurrentLevel++
+				} else {
+					GameWon = true
 				}
 			}
 		}
@@ -138,28 +143,49 @@ func (c *Creature) Move(tx, ty int, b Board) bool {
 	return turnSpent
 }
 
-func (c *Creature) PickUp(b Board) bool {
+func (c *Creature) CanMoveTo(x, y int, b Board) bool {
+	/* CanMoveTo is method of Creature that checks if coords passed
+	   as arguments are within map bounds, and if tile is not blocked.
+	   It does not check for other creatures. */
+	if x < 0 || x > MapSizeX-1 || y < 0 || y > MapSizeY-1 {
+		return false
+	}
+	return b[x][y].Blocked == false
+}
+
+func (c *Creature) EnterTile(b Board) {
+	/* EnterTile is called every time Creature enters new tile.
+	   It resolves effects of tile - for now, only hazards, that
+	   deal HazardDamage and apply HazardEffect. */
+	if b[c.X][c.Y].Hazard == true {
+		c.TakeDamage(HazardDamage)
+		c.ApplyEffect(HazardEffect, 0)
+	}
+}
+
+func (c *Creature) PickUp(b Board, o *Objects) bool {
 	/* PickUp is method that has *Creature as receiver.
 	   It will use *Tile as argument.
 	   The idea is to check, if tile has deposits of mana first,
-	   then allow player to "charge" energy from this deposit. */
+	   then allow player to "charge" energy from this deposit.
+	   Resources are objects too (see DrainResource); other
+	   objects are picked up, and taken out of the map. */
 	turnSpent := false
-	t := b[c.X][c.Y]
-	if t.Drained == true {
+	object := FindObjectByXY(c.X, c.Y, *o)
+	if object == nil || (object.Kind == ObjectResource && object.Pickable == false) {
+		AddMessage("there is nothing to pick up.", MessageColorBad)
 		return turnSpent
 	}
-	if (t.Resources == BallisticResource && c.Ballistic < AmmoMax) ||
-		(t.Resources == ExplosiveResource && c.Explosive < AmmoMax) ||
-		(t.Resources == KineticResource && c.Kinetic < AmmoMax) ||
-		(t.Resources == ElectromagneticResource &&
-			c.Electromagnetic < AmmoMax) {
-		c.AddAmmo(t.Resources)
-	} else {
+	if object.Kind == ObjectResource {
+		return c.DrainResource(object)
+	}
+	if c.PickUpObject(object) == true {
+		AddMessage("you pick up "+object.Name+".", MessageColorGood)
+		o.Remove(object)
+		turnSpent = true
 		return turnSpent
 	}
-	t.Drained = true
-	t.Color = ResourcesColors[t.Resources][1]
-	turnSpent = true
+	AddMessage("you can not use "+object.Name+" now.", MessageColorBad)
 	return turnSpent
 }
 
@@ -167,11 +193,29 @@ func (c *Creature) Die() {
 	/* Method Die is called when Creature's HP drops below zero.
 	   Die() has *Creature as receiver.
 	   Receiver properties changes to fit better to corpse. */
+	if c.AIType == PlayerAI {
+		AddMessage("you die...", MessageColorBad)
+	} else {
+		AddMessage(c.LogName()+" dies.", MessageColorGood)
+		Kills++
+	}
 	c.Layer = DeadLayer
 	c.Name = "corpse of " + c.Name
 	c.Blocked = false
 	c.BlocksSight = false
 	c.AIType = NoAI
+	c.Effects = nil
+}
+
+func (c *Creature) HasTag(tag string) bool {
+	/* HasTag returns true if receiver is described by tag
+	   passed as argument. */
+	for _, v := range c.Tags {
+		if v == tag {
+			return true
+		}
+	}
+	return false
 }
 
 func FindMonsterByXY(x, y int, c Creatures) *Creature {
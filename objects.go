@@ -0,0 +1,216 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	/* Kinds of objects. Ammo objects give Amount of ammo of DamageType
+	   (of active weapon, if DamageType is empty); healing objects restore
+	   Amount of HP. Both are used immediately when picked up.
+	   Consumables are stored in inventory (see inventory.go).
+	   Resources are deposits of ammo of DamageType placed by level
+	   generator; they are not removed when drained. */
+	ObjectAmmo       = "ammo"
+	ObjectHealing    = "healing"
+	ObjectConsumable = "consumable"
+	ObjectResource   = "resource"
+)
+
+type ObjectProperties struct {
+	/* ObjectProperties stores information about items lying on the
+	   map. Kind is one of Object* constants; Pickable marks objects
+	   that may be picked up by player. Use is effect of consumable,
+	   one of Use* constants. */
+	Kind       string
+	Pickable   bool
+	Use        string
+	Amount     int
+	DamageType string
+}
+
+type Object struct {
+	/* Objects are things that are neither part of map, nor
+	   living creatures - consumables, keys, barrels, etc. */
+	BasicProperties
+	VisibilityProperties
+	CollisionProperties
+	ObjectProperties
+}
+
+// Objects holds every object on map.
+type Objects []*Object
+
+func NewObject(x, y int, objectFile string) (*Object, error) {
+	/* NewObject is function that returns new Object from
+	   json file passed as argument. As in NewCreature, there is
+	   lazy panic for json errors. */
+	var object = &Object{}
+	err := ObjectFromJson(ObjectsPathJson+objectFile, object)
+	if err != nil {
+		fmt.Println(err)
+		panic(-1)
+	}
+	object.X, object.Y = x, y
+	var err2 error
+	if object.Layer < 0 {
+		txt := LayerError(object.Layer)
+		err2 = errors.New("Object layer is smaller than 0." + txt)
+	}
+	if object.Layer != ObjectsLayer {
+		txt := LayerWarning(object.Layer, ObjectsLayer)
+		err2 = errors.New("Object layer is not equal to ObjectsLayer constant." + txt)
+	}
+	if object.X < 0 || object.X >= MapSizeX || object.Y < 0 || object.Y >= MapSizeY {
+		txt := CoordsError(object.X, object.Y)
+		err2 = errors.New("Object coords is out of window range." + txt)
+	}
+	if utf8.RuneCountInString(object.Char) != 1 {
+		txt := CharacterLengthError(object.Char)
+		err2 = errors.New("Object character string length is not equal to 1." + txt)
+	}
+	if object.Kind != ObjectAmmo && object.Kind != ObjectHealing &&
+		object.Kind != ObjectConsumable {
+		txt := ObjectKindError(object.Name, object.Kind)
+		err2 = errors.New("Unknown kind of object." + txt)
+	}
+	if object.DamageType != "" {
+		if _, ok := DamageTypeByName(object.DamageType); ok == false {
+			txt := DamageTypeNameError(object.DamageType)
+			err2 = errors.New("Object uses unknown damage type." + txt)
+		}
+	}
+	if object.Kind == ObjectConsumable && object.Use != UseTeleport &&
+		object.Use != UseShield && object.Use != UseGrenade &&
+		object.Use != UseConverter {
+		txt := ObjectUseError(object.Name, object.Use)
+		err2 = errors.New("Unknown use of consumable." + txt)
+	}
+	return object, err2
+}
+
+func NewResource(x, y int, d DamageType) *Object {
+	/* NewResource returns ammo deposit of damage type passed as
+	   argument. Resources are not stored in json files - they
+	   look like icon of their damage type. */
+	resourceBasicProperties := BasicProperties{x, y, d.Icon,
+		d.Name + " resource", d.ColorGood, d.ColorBad}
+	resourceVisibilityProperties := VisibilityProperties{ObjectsLayer, true}
+	resourceCollisionProperties := CollisionProperties{false, false}
+	resourceObjectProperties := ObjectProperties{ObjectResource, true, "",
+		0, d.Name}
+	return &Object{resourceBasicProperties, resourceVisibilityProperties,
+		resourceCollisionProperties, resourceObjectProperties}
+}
+
+func FindObjectByXY(x, y int, o Objects) *Object {
+	/* Function FindObjectByXY returns the topmost object lying
+	   on specified tile, or nil. */
+	var object *Object
+	for _, v := range o {
+		if v.X == x && v.Y == y {
+			object = v
+		}
+	}
+	return object
+}
+
+func (o *Objects) Remove(object *Object) {
+	/* Remove takes object out of the map. */
+	for i, v := range *o {
+		if v == object {
+			*o = append((*o)[:i], (*o)[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Creature) PickUpObject(object *Object) bool {
+	/* PickUpObject applies object picked up by receiver.
+	   Objects that would be wasted (full ammo, full HP) are
+	   left on map. Returns true if object should be removed. */
+	if object.Pickable == false {
+		return false
+	}
+	switch object.Kind {
+	case ObjectAmmo:
+		name := object.DamageType
+		if name == "" {
+			if c.Active < 0 || c.Active >= len(DamageTypes) {
+				return false
+			}
+			name = DamageTypes[c.Active].Name
+		}
+		if c.Ammo == nil {
+			c.Ammo = map[string]int{}
+		}
+		if c.Ammo[name] >= c.AmmoCap(name) {
+			return false
+		}
+		c.Ammo[name] += object.Amount
+		if c.Ammo[name] > c.AmmoCap(name) {
+			c.Ammo[name] = c.AmmoCap(name)
+		}
+		return true
+	case ObjectHealing:
+		heal := object.Amount
+		if c.HasPassive(PassiveFieldMedic) == true {
+			heal++
+		}
+		return c.Heal(heal) > 0
+	case ObjectConsumable:
+		return c.AddToInventory(object)
+	}
+	return false
+}
+
+func (c *Creature) DrainResource(object *Object) bool {
+	/* DrainResource gives receiver ammo from resource (see AddAmmo).
+	   Drained resource stays on map, in its ColorBad.
+	   Returns "turn spent" marker. */
+	name := object.DamageType
+	if object.Pickable == false {
+		return false
+	}
+	if c.Ammo[name] >= c.AmmoCap(name) {
+		AddMessage("you can not carry more "+name+" ammo.", MessageColorBad)
+		return false
+	}
+	before := c.Ammo[name]
+	c.AddAmmo(name)
+	AddMessage("you collect "+strconv.Itoa(c.Ammo[name]-before)+" "+
+		name+" ammo.", MessageColorGood)
+	object.Pickable = false
+	object.Name = name + " resource (drained)"
+	object.Color = object.ColorDark
+	return true
+}
@@ -29,20 +29,33 @@ package main
 import (
 	blt "bearlibterminal"
 	"bufio"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
 const (
-	// Keyboard layout values used as identifiers in main.go.
-	KB_QWERTY = iota
-	KB_QWERTZ
-	KB_AZERTY
-	KB_Dvorak
+	/* QWERTY is built-in keyboard layout, always available as
+	   the first one. Other layouts are loaded from data files. */
+	KB_QWERTY = 0
 )
 
+// Names of keyboard layouts, as used in options_controls.cfg.
+var LayoutNames = []string{"QWERTY"}
+
+// Characters mappings of keyboard layouts, in order of LayoutNames.
+var KeyboardLayouts = []map[rune]int{QWERTYLayoutRunesToCodes}
+
+// Path to the config file.
+const OptionsControlsPath = "options_controls.cfg"
+
 /* KeyMap stores current characters mapping, therefore it content
    can be different every run. */
 var KeyMap map[rune]int
@@ -86,6 +99,44 @@ var HardcodedKeys = []int{
 	blt.TK_CLOSE, // Do not use in config file!
 }
 
+/* SpecialKeys maps names of keys that are not characters (as used
+   in options_controls.cfg) to scancodes. */
+var SpecialKeys = map[string]int{
+	"RETURN":      blt.TK_RETURN,
+	"ENTER":       blt.TK_ENTER,
+	"TAB":         blt.TK_TAB,
+	"BACKSPACE":   blt.TK_BACKSPACE,
+	"SPACE":       blt.TK_SPACE,
+	"COMMA":       blt.TK_COMMA,
+	"PAUSE":       blt.TK_PAUSE,
+	"INSERT":      blt.TK_INSERT,
+	"HOME":        blt.TK_HOME,
+	"PAGEUP":      blt.TK_PAGEUP,
+	"DELETE":      blt.TK_DELETE,
+	"END":         blt.TK_END,
+	"PAGEDOWN":    blt.TK_PAGEDOWN,
+	"RIGHT":       blt.TK_RIGHT,
+	"LEFT":        blt.TK_LEFT,
+	"DOWN":        blt.TK_DOWN,
+	"UP":          blt.TK_UP,
+	"KP_DIVIDE":   blt.TK_KP_DIVIDE,
+	"KP_MULTIPLY": blt.TK_KP_MULTIPLY,
+	"KP_MINUS":    blt.TK_KP_MINUS,
+	"KP_PLUS":     blt.TK_KP_PLUS,
+	"KP_ENTER":    blt.TK_KP_ENTER,
+	"KP_1":        blt.TK_KP_1,
+	"KP_2":        blt.TK_KP_2,
+	"KP_3":        blt.TK_KP_3,
+	"KP_4":        blt.TK_KP_4,
+	"KP_5":        blt.TK_KP_5,
+	"KP_6":        blt.TK_KP_6,
+	"KP_7":        blt.TK_KP_7,
+	"KP_8":        blt.TK_KP_8,
+	"KP_9":        blt.TK_KP_9,
+	"KP_0":        blt.TK_KP_0,
+	"KP_PERIOD":   blt.TK_KP_PERIOD,
+}
+
 /* The default keyboard layout.
    Using runes is - in that case - less prone to errors than strings. */
 var QWERTYLayoutRunesToCodes = map[rune]int{
@@ -181,384 +232,425 @@ var QWERTYLayoutRunesToCodes = map[rune]int{
 	'+':  blt.TK_EQUALS,
 }
 
-// Will be initialized on game start, based on QWERTY layout.
-var QWERTZLayoutRunesToCodes = map[rune]int{}
-var AZERTYLayoutRunesToCodes = map[rune]int{}
-
-// Initialized on game start as well, but the Dvorak is non QWERTY-based layout.
-var DvorakLayoutRunesToCodes map[rune]int
+type KeyboardLayoutData struct {
+	/* KeyboardLayoutData is keyboard layout as stored in data file.
+	   Keys maps characters to keys of QWERTY keyboard that are placed
+	   in the same place (like "z": "y" in QWERTZ), so values are
+	   characters of QWERTY layout. Both cases of letters are added
+	   automatically, unless they are listed separately. */
+	Name string
+	Keys map[string]string
+}
 
 func InitializeKeyboardLayouts() {
-	/* Function InitializeKeyboardLayouts initalizes all layout maps
-	   at the start of the game. */
-	InitializeQWERTZ()
-	InitializeAZERTY()
-	InitializeDvorak()
+	/* Function InitializeKeyboardLayouts loads all keyboard layouts
+	   installed in data/layouts at the start of the game.
+	   Broken layouts, or their entries, are printed and skipped -
+	   they can always fall back to QWERTY. */
+	files, err := filepath.Glob(LayoutsPathJson + "*.json")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		var data = KeyboardLayoutData{}
+		err := LayoutFromJson(f, &data)
+		if err != nil {
+			fmt.Println(errors.New("Can not read keyboard layout " + f + ".\n    " +
+				err.Error()))
+			continue
+		}
+		data.Name = strings.ToUpper(strings.TrimSpace(data.Name))
+		if data.Name == "" {
+			name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+			data.Name = strings.ToUpper(name)
+		}
+		duplicate := false
+		for _, v := range LayoutNames {
+			if v == data.Name {
+				duplicate = true
+			}
+		}
+		if duplicate == true {
+			fmt.Println(errors.New("Keyboard layout " + data.Name +
+				" is installed already; " + f + " is skipped."))
+			continue
+		}
+		LayoutNames = append(LayoutNames, data.Name)
+		KeyboardLayouts = append(KeyboardLayouts, newKeyboardLayout(data))
+	}
+}
+
+func newKeyboardLayout(data KeyboardLayoutData) map[rune]int {
+	/* Function newKeyboardLayout converts layout data to map of
+	   characters to QWERTY scancodes, like QWERTYLayoutRunesToCodes. */
+	var layout = map[rune]int{}
+	var explicit = map[rune]bool{}
+	for k, v := range data.Keys {
+		code, ok := 0, false
+		if utf8.RuneCountInString(v) == 1 {
+			code, ok = QWERTYLayoutRunesToCodes[[]rune(v)[0]]
+		}
+		if utf8.RuneCountInString(k) != 1 || ok == false {
+			txt := LayoutKeyError(data.Name, k, v)
+			fmt.Println(errors.New("Wrong entry in keyboard layout." + txt))
+			continue
+		}
+		r := []rune(k)[0]
+		layout[r] = code
+		explicit[r] = true
+	}
+	for r, code := range layout {
+		for _, v := range []rune{unicode.ToUpper(r), unicode.ToLower(r)} {
+			if explicit[v] == false {
+				layout[v] = code
+			}
+		}
+	}
+	return layout
 }
 
 func ChooseKeyboardLayout() {
 	/* Chooses keyboard layout based on value in options_controls.cfg. */
-	switch KeyboardLayout {
-	case KB_QWERTY:
-		KeyMap = QWERTYLayoutRunesToCodes
-	case KB_QWERTZ:
-		KeyMap = QWERTZLayoutRunesToCodes
-	case KB_AZERTY:
-		KeyMap = AZERTYLayoutRunesToCodes
-	case KB_Dvorak:
-		KeyMap = DvorakLayoutRunesToCodes
+	if KeyboardLayout < 0 || KeyboardLayout >= len(KeyboardLayouts) {
+		KeyboardLayout = KB_QWERTY
 	}
+	KeyMap = KeyboardLayouts[KeyboardLayout]
 }
 
-func InitializeQWERTZ() {
-	/* Function InitializeQWERTZ copies QWERTY layout,
-	   then changes values specific to QWERTZ layout.
-	   Additional keys, not included in QWERTY keyboards, are ignored. */
-	for k, v := range QWERTYLayoutRunesToCodes {
-		QWERTZLayoutRunesToCodes[k] = v
+type OptionsError struct {
+	/* OptionsError describes single problem found in config file.
+	   Line is 0 if problem is not related to any line. Warnings
+	   are problems that do not make config invalid. */
+	Line    int
+	Text    string
+	Warning bool
+}
+
+func (e OptionsError) Error() string {
+	txt := "error: "
+	if e.Warning == true {
+		txt = "warning: "
+	}
+	if e.Line > 0 {
+		txt = "line " + strconv.Itoa(e.Line) + ": " + txt
 	}
-	QWERTZLayoutRunesToCodes['z'] = blt.TK_Y
-	QWERTZLayoutRunesToCodes['Z'] = blt.TK_Y
-	QWERTZLayoutRunesToCodes['y'] = blt.TK_Z
-	QWERTZLayoutRunesToCodes['Y'] = blt.TK_Z
-	QWERTZLayoutRunesToCodes[';'] = blt.TK_COMMA
-	QWERTZLayoutRunesToCodes[':'] = blt.TK_PERIOD
-	QWERTZLayoutRunesToCodes['-'] = blt.TK_SLASH
-	QWERTZLayoutRunesToCodes['_'] = blt.TK_SLASH
-	QWERTZLayoutRunesToCodes['ö'] = blt.TK_SEMICOLON
-	QWERTZLayoutRunesToCodes['Ö'] = blt.TK_SEMICOLON
-	QWERTZLayoutRunesToCodes['ä'] = blt.TK_APOSTROPHE
-	QWERTZLayoutRunesToCodes['Ä'] = blt.TK_APOSTROPHE
-	QWERTZLayoutRunesToCodes['ü'] = blt.TK_LBRACKET
-	QWERTZLayoutRunesToCodes['Ü'] = blt.TK_LBRACKET
-	QWERTZLayoutRunesToCodes['+'] = blt.TK_RBRACKET
-	QWERTZLayoutRunesToCodes['*'] = blt.TK_RBRACKET
-	QWERTZLayoutRunesToCodes['"'] = blt.TK_2
-	QWERTZLayoutRunesToCodes['§'] = blt.TK_3
-	QWERTZLayoutRunesToCodes['&'] = blt.TK_6
-	QWERTZLayoutRunesToCodes['/'] = blt.TK_7
-	QWERTZLayoutRunesToCodes['('] = blt.TK_8
-	QWERTZLayoutRunesToCodes[')'] = blt.TK_9
-	QWERTZLayoutRunesToCodes['='] = blt.TK_0
-	QWERTZLayoutRunesToCodes['ß'] = blt.TK_MINUS
-	QWERTZLayoutRunesToCodes['?'] = blt.TK_MINUS
-	QWERTZLayoutRunesToCodes['´'] = blt.TK_EQUALS
-	QWERTZLayoutRunesToCodes['`'] = blt.TK_EQUALS
+	return txt + e.Text
 }
 
-func InitializeAZERTY() {
-	/* Function InitializeAZERTY copies QWERTY layout,
-	   then changes values specific to QWERTZ layout.
-	   Additional keys, not included in QWERTY keyboards, are ignored. */
-	for k, v := range QWERTYLayoutRunesToCodes {
-		AZERTYLayoutRunesToCodes[k] = v
+func ReadOptionsControls() {
+	/* Function ReadOptionsControls reads options_controls.cfg, and
+	   prints problems found there. It never stops the game: bad
+	   entries are replaced by default values. */
+	for _, v := range ParseOptionsControls(OptionsControlsPath) {
+		fmt.Println(OptionsControlsPath + ": " + v.Error())
 	}
-	AZERTYLayoutRunesToCodes['a'] = blt.TK_Q
-	AZERTYLayoutRunesToCodes['A'] = blt.TK_Q
-	AZERTYLayoutRunesToCodes['z'] = blt.TK_W
-	AZERTYLayoutRunesToCodes['Z'] = blt.TK_W
-	AZERTYLayoutRunesToCodes['q'] = blt.TK_A
-	AZERTYLayoutRunesToCodes['Q'] = blt.TK_A
-	AZERTYLayoutRunesToCodes['w'] = blt.TK_Z
-	AZERTYLayoutRunesToCodes['W'] = blt.TK_Z
-	AZERTYLayoutRunesToCodes[','] = blt.TK_M
-	AZERTYLayoutRunesToCodes['?'] = blt.TK_M
-	AZERTYLayoutRunesToCodes[';'] = blt.TK_COMMA
-	AZERTYLayoutRunesToCodes['.'] = blt.TK_COMMA
-	AZERTYLayoutRunesToCodes[':'] = blt.TK_PERIOD
-	AZERTYLayoutRunesToCodes['/'] = blt.TK_PERIOD
-	AZERTYLayoutRunesToCodes['!'] = blt.TK_SLASH
-	AZERTYLayoutRunesToCodes['§'] = blt.TK_SLASH
-	AZERTYLayoutRunesToCodes['m'] = blt.TK_SEMICOLON
-	AZERTYLayoutRunesToCodes['M'] = blt.TK_SEMICOLON
-	AZERTYLayoutRunesToCodes['ù'] = blt.TK_APOSTROPHE
-	AZERTYLayoutRunesToCodes['%'] = blt.TK_APOSTROPHE
-	AZERTYLayoutRunesToCodes['^'] = blt.TK_LBRACKET
-	AZERTYLayoutRunesToCodes['¨'] = blt.TK_LBRACKET
-	AZERTYLayoutRunesToCodes['$'] = blt.TK_RBRACKET
-	AZERTYLayoutRunesToCodes['£'] = blt.TK_RBRACKET
-	AZERTYLayoutRunesToCodes['&'] = blt.TK_1
-	AZERTYLayoutRunesToCodes['é'] = blt.TK_2
-	AZERTYLayoutRunesToCodes['"'] = blt.TK_3
-	AZERTYLayoutRunesToCodes['\''] = blt.TK_4
-	AZERTYLayoutRunesToCodes['('] = blt.TK_5
-	AZERTYLayoutRunesToCodes['-'] = blt.TK_6
-	AZERTYLayoutRunesToCodes['è'] = blt.TK_7
-	AZERTYLayoutRunesToCodes['_'] = blt.TK_8
-	AZERTYLayoutRunesToCodes['ç'] = blt.TK_9
-	AZERTYLayoutRunesToCodes['à'] = blt.TK_0
-	AZERTYLayoutRunesToCodes[')'] = blt.TK_MINUS
-	AZERTYLayoutRunesToCodes['°'] = blt.TK_MINUS
 }
 
-func InitializeDvorak() {
-	/* Initializing Dvorak layout is different to previously implemented
-	   schemes. As it's not QWERTY-based layout, it does not make a sense
-	   to copy QWERTY map and change specific values. Instead, whole layout
-	   is defined as new one, at once.
-	   It is stored in specific function because I did not want to flood
-	   top of the file more than necessary. */
-	DvorakLayoutRunesToCodes = map[rune]int{
-		'\'': blt.TK_Q,
-		'"':  blt.TK_Q,
-		',':  blt.TK_W,
-		'<':  blt.TK_W,
-		'.':  blt.TK_E,
-		'>':  blt.TK_E,
-		'p':  blt.TK_R,
-		'P':  blt.TK_R,
-		'y':  blt.TK_T,
-		'Y':  blt.TK_T,
-		'f':  blt.TK_Y,
-		'F':  blt.TK_Y,
-		'g':  blt.TK_U,
-		'G':  blt.TK_U,
-		'c':  blt.TK_I,
-		'C':  blt.TK_I,
-		'r':  blt.TK_O,
-		'R':  blt.TK_O,
-		'l':  blt.TK_P,
-		'L':  blt.TK_P,
-		'a':  blt.TK_A,
-		'A':  blt.TK_A,
-		'o':  blt.TK_S,
-		'O':  blt.TK_S,
-		'e':  blt.TK_D,
-		'E':  blt.TK_D,
-		'u':  blt.TK_F,
-		'U':  blt.TK_F,
-		'i':  blt.TK_G,
-		'I':  blt.TK_G,
-		'd':  blt.TK_H,
-		'D':  blt.TK_H,
-		'h':  blt.TK_J,
-		'H':  blt.TK_J,
-		't':  blt.TK_K,
-		'T':  blt.TK_K,
-		'n':  blt.TK_L,
-		'N':  blt.TK_L,
-		';':  blt.TK_Z,
-		':':  blt.TK_Z,
-		'q':  blt.TK_X,
-		'Q':  blt.TK_X,
-		'j':  blt.TK_C,
-		'J':  blt.TK_C,
-		'k':  blt.TK_V,
-		'K':  blt.TK_V,
-		'x':  blt.TK_B,
-		'X':  blt.TK_B,
-		'b':  blt.TK_N,
-		'B':  blt.TK_N,
-		'm':  blt.TK_M,
-		'M':  blt.TK_M,
-		'w':  blt.TK_COMMA,
-		'W':  blt.TK_COMMA,
-		'v':  blt.TK_PERIOD,
-		'V':  blt.TK_PERIOD,
-		'z':  blt.TK_SLASH,
-		'Z':  blt.TK_SLASH,
-		's':  blt.TK_SEMICOLON,
-		'S':  blt.TK_SEMICOLON,
-		'-':  blt.TK_APOSTROPHE,
-		'_':  blt.TK_APOSTROPHE,
-		'/':  blt.TK_LBRACKET,
-		'?':  blt.TK_LBRACKET,
-		'=':  blt.TK_RBRACKET,
-		'+':  blt.TK_RBRACKET,
-		'1':  blt.TK_1,
-		'!':  blt.TK_1,
-		'2':  blt.TK_2,
-		'@':  blt.TK_2,
-		'3':  blt.TK_3,
-		'#':  blt.TK_3,
-		'4':  blt.TK_4,
-		'$':  blt.TK_4,
-		'5':  blt.TK_5,
-		'%':  blt.TK_5,
-		'6':  blt.TK_6,
-		'^':  blt.TK_6,
-		'7':  blt.TK_7,
-		'&':  blt.TK_7,
-		'8':  blt.TK_8,
-		'*':  blt.TK_8,
-		'9':  blt.TK_9,
-		'(':  blt.TK_9,
-		'0':  blt.TK_0,
-		')':  blt.TK_0,
-		'[':  blt.TK_MINUS,
-		'{':  blt.TK_MINUS,
-		']':  blt.TK_EQUALS,
-		'}':  blt.TK_EQUALS,
+func OptionsErrorsCount(errs []OptionsError) int {
+	/* Function OptionsErrorsCount returns number of problems
+	   that are not warnings. */
+	n := 0
+	for _, v := range errs {
+		if v.Warning == false {
+			n++
+		}
 	}
+	return n
 }
 
-func ReadOptionsControls() {
-	/* Function ReadOptionsControls reads specific file and handles
-	   controls-related settings.
-	   At first, it tries to open options_controls.cfg and panics if
-	   this action fails (it could load generic QWERTY scheme instead, though).
-	   Scans whole file, splits it into newlines, ignores every line started
+func resetOptionsControls() {
+	/* Function resetOptionsControls sets all options to default
+	   values; custom controls are empty. */
+	KeyboardLayout = KB_QWERTY
+	CustomControls = false
+	TargetingPreview = false
+	AnimationSpeed = AnimationsNormal
+	CustomCommandKeys = map[int]string{}
+}
+
+func ParseOptionsControls(path string) []OptionsError {
+	/* Function ParseOptionsControls reads config file and handles
+	   controls-related settings. Problems are collected, with line
+	   numbers, and returned; nothing panics.
+	   Scans whole file, ignores empty lines and every line started
 	   by # character (it means it is the comment), then splits every
-	   line on = character. Left side is key, right - value, so at the end
-	   it works a bit like a map or dictionary.
+	   line on the first = character. Left side is key, right - value,
+	   so at the end it works a bit like a map or dictionary.
 	   To make editing config file less prone to errors, every string is
 	   trimmed of whitespaces and capitalized.
 	   Possible actions and values are listed in config file, as comments.
-	   If value of KB_LAYOUT is wrong, it falls back to QWERTY scheme.
-	   If controls scheme is set to custom (in case of problems it falls back
-	   to false) it uses private addKeyToCustomLayout function to
-	   create CustomCommandKeys (see controls.go). */
-	f, err := os.Open("options_controls.cfg")
+	   Missing file, or wrong values, fall back to defaults: QWERTY scheme,
+	   default controls, no targeting preview, normal animations.
+	   Actions of custom controls scheme that have no valid keys use
+	   keys of default controls scheme, if they are not taken.
+	   Unknown keys, and keys that appear twice (only the first is used),
+	   are reported as warnings.
+	   TARGETING_PREVIEW enables two-step shooting (see targeting.go).
+	   ANIMATIONS sets speed of animations (see animation.go). */
+	resetOptionsControls()
+	var errs = []OptionsError{}
+	f, err := os.Open(path)
 	if err != nil {
-		panic("Can't find options_controls.cfg file!")
+		errs = append(errs, OptionsError{0, "can not read file (" +
+			err.Error() + "); using default options.", false})
+		return errs
 	}
 	defer f.Close()
-	var opts = []string{}
+	var seen = map[string]int{}
+	var bound = map[string]bool{}
+	n := 0
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		var lines = []string{}
-		line := scanner.Text()
-		if utf8.RuneCountInString(line) > 0 && []rune(line)[0] != '#' {
-			line = strings.Replace(line, "\r", "\n", -1)
-			lines = strings.Split(line, "\n")
-			for i := 0; i < len(lines); i++ {
-				opts = append(opts, strings.ToUpper(strings.TrimSpace(lines[i])))
-			}
+		n++
+		line := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		results := strings.SplitN(line, "=", 2)
+		if len(results) < 2 {
+			errs = append(errs, OptionsError{n, "expected KEY = VALUE, got \"" +
+				line + "\".", false})
+			continue
 		}
-	}
-	for _, v := range opts {
-		var results = strings.Split(v, "=")
 		resKey := strings.TrimSpace(results[0])
-		if resKey == "KB_LAYOUT" {
-			val := strings.TrimSpace(results[1])
-			switch val {
-			case "QWERTY":
-				KeyboardLayout = KB_QWERTY
-			case "QWERTZ":
-				KeyboardLayout = KB_QWERTZ
-			case "AZERTY":
-				KeyboardLayout = KB_AZERTY
-			case "DVORAK":
-				KeyboardLayout = KB_Dvorak
-			default:
-				fmt.Println("Wrong value in KB_LAYOUT; using QWERTY.")
-				KeyboardLayout = KB_QWERTY
+		resValue := strings.TrimSpace(results[1])
+		if first, ok := seen[resKey]; ok == true {
+			errs = append(errs, OptionsError{n, resKey + " is already set in line " +
+				strconv.Itoa(first) + "; this line is ignored.", true})
+			continue
+		}
+		seen[resKey] = n
+		txt, ok := setOption(resKey, resValue)
+		if ok == true && txt != "" {
+			errs = append(errs, OptionsError{n, txt, false})
+			continue
+		} else if ok == true {
+			continue
+		}
+		valid := false
+		for _, v := range Actions {
+			if resKey == v {
+				valid = true
 			}
-		} else if resKey == "CUSTOM_CONTROLS" {
-			val := strings.TrimSpace(results[1])
-			if val == "TRUE" {
-				CustomControls = true
-			} else if val == "FALSE" {
-				CustomControls = false
-			} else {
-				fmt.Println("Wrong value is CUSTOM_CONTROLS; using FALSE.")
-				CustomControls = false
+		}
+		if valid == false {
+			errs = append(errs, OptionsError{n, "unknown key " + resKey +
+				"; this line is ignored.", true})
+			continue
+		}
+		for _, v := range addKeyToCustomLayout(resKey, resValue) {
+			v.Line = n
+			errs = append(errs, v)
+		}
+		bound[resKey] = CustomKeyNames(resKey) != ""
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, OptionsError{n, "can not read file (" +
+			err.Error() + ").", false})
+	}
+	for _, v := range Actions {
+		if bound[v] == true {
+			continue
+		}
+		for k, com := range CommandKeys {
+			if _, taken := CustomCommandKeys[k]; com == v && taken == false {
+				CustomCommandKeys[k] = v
 			}
 		}
+		if CustomControls == true {
+			errs = append(errs, OptionsError{0, v + " has no keys; using " +
+				CustomKeyNames(v) + ".", true})
+		}
 	}
-	for _, v := range opts {
-		var results = strings.Split(v, "=")
-		resKey := strings.TrimSpace(results[0])
-		resValue := strings.TrimSpace(results[1])
-		if utf8.RuneCountInString(resKey) > 0 && []rune(resKey)[0] != '#' &&
-			resKey != "KB_LAYOUT" && resKey != "CUSTOM_CONTROLS" {
-			addKeyToCustomLayout(resKey, resValue)
+	return errs
+}
+
+func setOption(resKey, resValue string) (string, bool) {
+	/* Function setOption sets option (that is not action) from
+	   config file. Returns false if resKey is not an option;
+	   returns description of problem if value is wrong. */
+	switch resKey {
+	case "KB_LAYOUT":
+		for i, v := range LayoutNames {
+			if resValue == v {
+				KeyboardLayout = i
+				return "", true
+			}
+		}
+		KeyboardLayout = KB_QWERTY
+		return "wrong value in KB_LAYOUT: " + resValue + "; using QWERTY.", true
+	case "CUSTOM_CONTROLS":
+		if resValue == "TRUE" {
+			CustomControls = true
+		} else if resValue == "FALSE" {
+			CustomControls = false
+		} else {
+			CustomControls = false
+			return "wrong value in CUSTOM_CONTROLS: " + resValue + "; using FALSE.", true
 		}
+		return "", true
+	case "TARGETING_PREVIEW":
+		if resValue == "TRUE" {
+			TargetingPreview = true
+		} else if resValue == "FALSE" {
+			TargetingPreview = false
+		} else {
+			TargetingPreview = false
+			return "wrong value in TARGETING_PREVIEW: " + resValue + "; using FALSE.", true
+		}
+		return "", true
+	case "ANIMATIONS":
+		for i, v := range AnimationNames {
+			if resValue == v {
+				AnimationSpeed = i
+				return "", true
+			}
+		}
+		AnimationSpeed = AnimationsNormal
+		return "wrong value in ANIMATIONS: " + resValue + "; using NORMAL.", true
 	}
+	return "", false
 }
 
-func addKeyToCustomLayout(resKey string, resValue string) {
+func addKeyToCustomLayout(resKey string, resValue string) []OptionsError {
 	/* addKeyToCustomLayout uses key, value passed from options_controls.cfg.
 	   It uses internal blt scancodes (based on QWERTY layout) and adds
 	   rune as key and scancode as value in CustomCommandsKeys (in controls.go).
-	   Custom controls works with non-QWERTY schemes, but limits keys mapped
-	   to action to one key. */
-	var tempMap = map[rune]int{}
-	for k, v := range QWERTYLayoutRunesToCodes { //bc BLT uses QWERTY internally
-		tempMap[k] = v
+	   Custom controls works with non-QWERTY schemes.
+	   Value is comma-separated list of keys; every key may be
+	   prefixed by modifiers, like SHIFT+S. Wrong keys, and keys
+	   that are bound to other action already, are reported, and
+	   skipped. resKey has to be valid action. */
+	var errs = []OptionsError{}
+	for _, name := range strings.Split(resValue, ",") {
+		name = strings.TrimSpace(name)
+		i, ok := ParseKeyName(name)
+		if ok == false && name == "" {
+			errs = append(errs, OptionsError{0, "empty key in " + resKey +
+				" (use COMMA for , key).", false})
+			continue
+		} else if ok == false {
+			errs = append(errs, OptionsError{0, "wrong key \"" + name +
+				"\" in " + resKey + ".", false})
+			continue
+		}
+		if other, ok := CustomCommandKeys[i]; ok == true {
+			if other != resKey {
+				errs = append(errs, OptionsError{0, "key " + name + " is bound to " +
+					other + " and " + resKey + "; using " + other + ".", true})
+			}
+			continue
+		}
+		if reserved, ok := ReservedKeys[i]; ok == true {
+			errs = append(errs, OptionsError{0, "key " + name + " is reserved for " +
+				reserved + "; it will not trigger " + resKey + ".", true})
+		}
+		CustomCommandKeys[i] = resKey
+	}
+	return errs
+}
+
+func ParseKeyName(name string) (int, bool) {
+	/* Function ParseKeyName converts name of key, as used in
+	   options_controls.cfg, to scancode with modifier flags
+	   (see KeyName in controls.go). Returns false if name is
+	   not valid. */
+	code := 0
+	for {
+		mod := false
+		for k, v := range Modifiers {
+			if strings.HasPrefix(name, k+"+") && len(name) > len(k)+1 {
+				code = code | v
+				name = name[len(k)+1:]
+				mod = true
+			}
+		}
+		if mod == false {
+			break
+		}
+	}
+	if key, ok := SpecialKeys[name]; ok == true {
+		return code | key, true
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		//bc BLT uses QWERTY internally
+		if key, ok := QWERTYLayoutRunesToCodes[[]rune(name)[0]]; ok == true {
+			return code | key, true
+		}
+	}
+	return 0, false
+}
+
+func optionValue(key string) (string, bool) {
+	/* Function optionValue returns current value of option or action,
+	   formatted as in options_controls.cfg. Returns false if key
+	   is neither option nor action. */
+	switch key {
+	case "KB_LAYOUT":
+		return LayoutNames[KeyboardLayout], true
+	case "CUSTOM_CONTROLS":
+		return strings.ToUpper(strconv.FormatBool(CustomControls)), true
+	case "TARGETING_PREVIEW":
+		return strings.ToUpper(strconv.FormatBool(TargetingPreview)), true
+	case "ANIMATIONS":
+		return AnimationNames[AnimationSpeed], true
 	}
-	var s string
-	valid := false
 	for _, v := range Actions {
-		if resKey == v {
-			valid = true
+		if key == v {
+			return CustomKeyNames(v), true
 		}
 	}
-	if valid == true {
-		s = resKey
-	} else {
-		panic("Wrong value: " + resKey)
+	return "", false
+}
+
+func SaveOptionsControls() error {
+	/* Function SaveOptionsControls writes current options and custom
+	   controls back to options_controls.cfg. Comments, empty lines,
+	   and order of entries are kept; only values are replaced.
+	   Actions missing in file are appended at the end; actions
+	   without keys are commented out.
+	   File is written to temporary file first, then renamed, so
+	   failed write does not leave broken config. */
+	data, err := ioutil.ReadFile(OptionsControlsPath)
+	if err != nil && os.IsNotExist(err) == false {
+		return err
+	}
+	var lines = []string{}
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
 	}
-	var i int
-	switch resValue {
-	case "RETURN":
-		i = blt.TK_RETURN
-	case "ENTER":
-		i = blt.TK_ENTER
-	case "TAB":
-		i = blt.TK_TAB
-	case "SPACE":
-		i = blt.TK_SPACE
-	case "PAUSE":
-		i = blt.TK_PAUSE
-	case "INSERT":
-		i = blt.TK_INSERT
-	case "HOME":
-		i = blt.TK_HOME
-	case "PAGEUP":
-		i = blt.TK_PAGEUP
-	case "DELETE":
-		i = blt.TK_DELETE
-	case "END":
-		i = blt.TK_END
-	case "PAGEDOWN":
-		i = blt.TK_PAGEDOWN
-	case "RIGHT":
-		i = blt.TK_RIGHT
-	case "LEFT":
-		i = blt.TK_LEFT
-	case "DOWN":
-		i = blt.TK_DOWN
-	case "UP":
-		i = blt.TK_UP
-	case "KP_DIVIDE":
-		i = blt.TK_KP_DIVIDE
-	case "KP_MULTIPLY":
-		i = blt.TK_KP_MULTIPLY
-	case "KP_MINUS":
-		i = blt.TK_KP_MINUS
-	case "KP_PLUS":
-		i = blt.TK_KP_PLUS
-	case "KP_ENTER":
-		i = blt.TK_KP_ENTER
-	case "KP_1":
-		i = blt.TK_KP_1
-	case "KP_2":
-		i = blt.TK_KP_2
-	case "KP_3":
-		i = blt.TK_KP_3
-	case "KP_4":
-		i = blt.TK_KP_4
-	case "KP_5":
-		i = blt.TK_KP_5
-	case "KP_6":
-		i = blt.TK_KP_6
-	case "KP_7":
-		i = blt.TK_KP_7
-	case "KP_8":
-		i = blt.TK_KP_8
-	case "KP_9":
-		i = blt.TK_KP_9
-	case "KP_0":
-		i = blt.TK_KP_0
-	case "KP_PERIOD":
-		i = blt.TK_KP_PERIOD
-	default:
-		if utf8.RuneCountInString(resValue) == 1 {
-			i = tempMap[[]rune(resValue)[0]]
+	var written = map[string]bool{}
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		eq := strings.Index(line, "=")
+		if trimmed == "" || trimmed[0] == '#' || eq < 0 {
+			continue
+		}
+		key := strings.ToUpper(strings.TrimSpace(line[:eq]))
+		value, ok := optionValue(key)
+		if ok == false || written[key] == true {
+			continue
+		}
+		if value == "" {
+			// Action without keys is commented out, to keep config valid.
+			lines[i] = "# " + line[:eq+1]
 		} else {
-			panic("Wrong value: " + resValue)
+			lines[i] = line[:eq+1] + " " + value
 		}
+		written[key] = true
+	}
+	for _, v := range Actions {
+		if value, _ := optionValue(v); written[v] == false && value != "" {
+			lines = append(lines, v+" = "+value)
+		}
+	}
+	tmp := OptionsControlsPath + ".tmp"
+	err = ioutil.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	if err != nil {
+		return err
 	}
-	CustomCommandKeys[i] = s
+	return os.Rename(tmp, OptionsControlsPath)
 }
@@ -0,0 +1,230 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	blt "bearlibterminal"
+)
+
+func inTempDir(t *testing.T) func() {
+	/* Function inTempDir switches to empty temporary directory, as
+	   config is always read from and written to working directory.
+	   Returned function restores working directory and options. */
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Chdir(wd)
+		resetOptionsControls()
+	}
+}
+
+func TestSaveOptionsControls(t *testing.T) {
+	defer inTempDir(t)()
+	var tests = []struct {
+		name   string
+		before string
+		want   string
+	}{
+		{"comments are kept",
+			"# Controls\n\nKB_LAYOUT = QWERTY\n# move\nMOVE_NORTH = W\n",
+			"# Controls\n\nKB_LAYOUT = QWERTY\n# move\nMOVE_NORTH = K\n"},
+		{"action without keys is commented out",
+			"MOVE_NORTH = W\nMOVE_SOUTH = S\n",
+			"MOVE_NORTH = K\n# MOVE_SOUTH =\n"},
+		{"missing action is appended",
+			"# only comment\n",
+			"# only comment\nMOVE_NORTH = K\n"},
+		{"only the first entry is replaced",
+			"MOVE_NORTH = W\nMOVE_NORTH = X\n",
+			"MOVE_NORTH = K\nMOVE_NORTH = X\n"},
+		{"windows line endings",
+			"MOVE_NORTH = W\r\n",
+			"MOVE_NORTH = K\n"},
+		{"missing file", "", "MOVE_NORTH = K\n"},
+	}
+	for _, tt := range tests {
+		os.Remove(OptionsControlsPath)
+		if tt.before != "" {
+			err := ioutil.WriteFile(OptionsControlsPath, []byte(tt.before), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		resetOptionsControls()
+		CustomCommandKeys[blt.TK_K] = StrMoveNorth
+		if err := SaveOptionsControls(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got, err := ioutil.ReadFile(OptionsControlsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: saved %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeyName(t *testing.T) {
+	var tests = []struct {
+		name   string
+		want   int
+		wantOK bool
+	}{
+		{"A", blt.TK_A, true},
+		{"SHIFT+A", ModShift | blt.TK_A, true},
+		{"CTRL+ALT+A", ModCtrl | ModAlt | blt.TK_A, true},
+		{"SPACE", blt.TK_SPACE, true},
+		{"SHIFT+COMMA", ModShift | blt.TK_COMMA, true},
+		{"KP_5", blt.TK_KP_5, true},
+		{"", 0, false},
+		{"SHIFT+", 0, false},
+		{"FOO", 0, false},
+		{"SHIFT+FOO", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseKeyName(tt.name)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseKeyName(%q) = %d, %v; want %d, %v", tt.name, got, ok,
+				tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseOptionsControls(t *testing.T) {
+	/* Problems are compared by line and severity only;
+	   their wording is for player, not for tests. */
+	defer inTempDir(t)()
+	type problem struct {
+		line    int
+		warning bool
+	}
+	var tests = []struct {
+		name  string
+		cfg   string
+		want  []problem
+		check func() bool
+	}{
+		{"valid", "# comment\n\nTARGETING_PREVIEW = TRUE\nMOVE_NORTH = W, SHIFT+K\n",
+			nil, func() bool {
+				return TargetingPreview == true &&
+					CustomCommandKeys[blt.TK_W] == StrMoveNorth &&
+					CustomCommandKeys[ModShift|blt.TK_K] == StrMoveNorth
+			}},
+		{"lowercase and spaces", "  move_north =  w  \n", nil, func() bool {
+			return CustomCommandKeys[blt.TK_W] == StrMoveNorth
+		}},
+		{"not KEY = VALUE", "# comment\nMOVE_NORTH W\n",
+			[]problem{{2, false}}, nil},
+		{"set twice", "MOVE_NORTH = W\nMOVE_NORTH = K\n",
+			[]problem{{2, true}}, func() bool {
+				return CustomCommandKeys[blt.TK_K] != StrMoveNorth
+			}},
+		{"unknown action", "JUMP = J\n", []problem{{1, true}}, nil},
+		{"wrong option value", "\nKB_LAYOUT = FOO\n", []problem{{2, false}},
+			func() bool { return KeyboardLayout == KB_QWERTY }},
+		{"wrong key", "MOVE_NORTH = FOO\n", []problem{{1, false}}, nil},
+		{"empty key", "MOVE_NORTH = W,\n", []problem{{1, false}}, nil},
+		{"key bound twice", "MOVE_NORTH = W\nMOVE_SOUTH = W\n",
+			[]problem{{2, true}}, func() bool {
+				return CustomCommandKeys[blt.TK_W] == StrMoveNorth
+			}},
+		{"reserved key", "WAIT = SHIFT+S\n", []problem{{1, true}}, nil},
+		{"unbound action uses default keys", "", nil, func() bool {
+			return CustomCommandKeys[blt.TK_W] == CommandKeys[blt.TK_W]
+		}},
+	}
+	for _, tt := range tests {
+		err := ioutil.WriteFile(OptionsControlsPath, []byte(tt.cfg), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got = []problem{}
+		for _, v := range ParseOptionsControls(OptionsControlsPath) {
+			got = append(got, problem{v.Line, v.Warning})
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: problems %v, want %v", tt.name, got, tt.want)
+		} else {
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("%s: problems %v, want %v", tt.name, got, tt.want)
+					break
+				}
+			}
+		}
+		if tt.check != nil && tt.check() == false {
+			t.Errorf("%s: options not set as expected", tt.name)
+		}
+	}
+}
+
+func TestParseOptionsControlsMissingFile(t *testing.T) {
+	defer inTempDir(t)()
+	errs := ParseOptionsControls("missing.cfg")
+	if len(errs) != 1 || errs[0].Line != 0 || errs[0].Warning == true {
+		t.Errorf("got %v, want single error not related to line", errs)
+	}
+	if OptionsErrorsCount(errs) != 1 {
+		t.Errorf("OptionsErrorsCount = %d, want 1", OptionsErrorsCount(errs))
+	}
+}
+
+func TestNewKeyboardLayout(t *testing.T) {
+	qwerty := func(r rune) int { return QWERTYLayoutRunesToCodes[r] }
+	var tests = []struct {
+		name string
+		keys map[string]string
+		want map[rune]int
+	}{
+		{"both cases added", map[string]string{"z": "y"},
+			map[rune]int{'z': qwerty('y'), 'Z': qwerty('y')}},
+		{"explicit case kept", map[string]string{"a": "q", "A": "w"},
+			map[rune]int{'a': qwerty('q'), 'A': qwerty('w')}},
+		{"not a letter", map[string]string{";": "z"},
+			map[rune]int{';': qwerty('z')}},
+		{"wrong entries skipped", map[string]string{"ab": "q", "x": "??", "y": "€"},
+			map[rune]int{}},
+	}
+	for _, tt := range tests {
+		got := newKeyboardLayout(KeyboardLayoutData{"TEST", tt.keys})
+		if reflect.DeepEqual(got, tt.want) == false {
+			t.Errorf("%s: layout %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -109,6 +109,9 @@ func FindAdjacent(b Board, c Creatures, nodes [][]*Node, frontiers []*Node, star
 				if b[x][y].Blocked == true {
 					continue //tile is blocked
 				}
+				if b[x][y].Hazard == true {
+					continue //monsters avoid hazards
+				}
 				if GetAliveCreatureFromTile(x, y, c) != nil {
 					continue //tile is occupied by other monster
 				}
@@ -123,10 +126,10 @@ End:
 	return adjacent, startFound
 }
 
-func (c *Creature) MoveTowardsPath(b Board, cs Creatures, tx, ty int) {
-	/* MoveTowardsPath is one of main pathfinding methods. It takes
+func (c *Creature) PathTowards(b Board, cs Creatures, tx, ty int) (int, int, error) {
+	/* PathTowards is one of main pathfinding methods. It takes
 	   Board and ints tx, ty (ie target coords) as arguments.
-	   MoveTowardsPath uses weighted graph to find shortest path
+	   PathTowards uses weighted graph to find shortest path
 	   from goal (tx, ty - it's more universal than passing Node or
 	   Creature) to source (creature, ie receiver).
 	   At first, it creates simple graph with all nodes' Weight set to
@@ -140,7 +143,8 @@ func (c *Creature) MoveTowardsPath(b Board, cs Creatures, tx, ty int) {
 	   is end of path / graph, so Creature has only find node with
 	   Weight set to lesser value that node occupied by Creature.
 	   Effect may be a bit strange as it takes first node that met
-	   conditions, but works rather well with basic MoveTowards method. */
+	   conditions, but works rather well with basic MoveTowards method.
+	   Returns direction of the first step, and error from BacktrackPath. */
 	nodes := TilesToNodes()
 	start := nodes[c.X][c.Y]
 	startFound := false
@@ -157,7 +161,13 @@ func (c *Creature) MoveTowardsPath(b Board, cs Creatures, tx, ty int) {
 	}
 	// Uncomment line below, if you want to see nodes' weights.
 	//RenderWeights(nodes)
-	dx, dy, err := BacktrackPath(nodes, start)
+	return BacktrackPath(nodes, start)
+}
+
+func (c *Creature) MoveTowardsPath(b Board, cs Creatures, tx, ty int) {
+	/* MoveTowardsPath moves receiver one step towards tx, ty,
+	   using path found by PathTowards. */
+	dx, dy, err := c.PathTowards(b, cs, tx, ty)
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -170,7 +180,7 @@ func BacktrackPath(nodes [][]*Node, start *Node) (int, int, error) {
 	   as coords.
 	   BacktrackPath is used in pathfinding. It uses weighted graph
 	   that has some sort of path already created (more in comments for
-	   MoveTowardsPath and FindAdjacent). Instead of creating
+	   PathTowards and FindAdjacent). Instead of creating
 	   proper path, or using search algorithm, structure of graph
 	   allows to use just node with smaller Weight than start node.
 	   It returns error if can't find proper tile.
@@ -212,7 +222,7 @@ func RenderWeights(nodes [][]*Node) {
 	   of graph, then waits for user input to continue
 	   game loop.
 	   It's supposed to be called near the end of
-	   MoveTowardsPath method. */
+	   PathTowards method. */
 	blt.Clear()
 	for x := 0; x < MapSizeX; x++ {
 		for y := 0; y < MapSizeY; y++ {
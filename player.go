@@ -119,7 +119,7 @@ func (c *Creature) SetWeapon(i int) bool {
 	   If the newly selected weapon was active before player input,
 	   returns false; otherwise, returns true. */
 	i--
-	if i < 0 || i > 3 {
+	if i < 0 || i >= len(DamageTypes) {
 		return false
 	}
 	if i == c.Active {
@@ -130,29 +130,106 @@ func (c *Creature) SetWeapon(i int) bool {
 	}
 }
 
-func (c *Creature) AddAmmo(resource int) {
-	/* If player is standing on resource tile,
-	   may obtain randomly chosen number of ammo. */
-	switch resource {
-	case BallisticResource:
-		c.Ballistic += RandRange(1, 3)
-		if c.Ballistic > AmmoMax {
-			c.Ballistic = AmmoMax
+func (c *Creature) AddAmmo(resource string) {
+	/* If player is standing on resource,
+	   may obtain randomly chosen number of ammo.
+	   Range of obtained ammo is defined per damage type. */
+	i, ok := DamageTypeByName(resource)
+	if ok == false {
+		return
+	}
+	if c.Ammo == nil {
+		c.Ammo = map[string]int{}
+	}
+	c.Ammo[resource] += RandRange(DamageTypes[i].PickupMin, DamageTypes[i].PickupMax)
+	if c.HasPassive(PassiveScavenger) == true {
+		c.Ammo[resource]++
+	}
+	if c.Ammo[resource] > c.AmmoCap(resource) {
+		c.Ammo[resource] = c.AmmoCap(resource)
+	}
+}
+
+const (
+	// Values used by REST action.
+	RestMaxTurns = 20
+	RestDelay    = 50
+)
+
+/* Rest holds state of REST action: player repeats waiting until
+   something interesting happens. HP and Effects are used to notice
+   changes of player state. */
+var Rest = struct {
+	Active  bool
+	Turns   int
+	HP      int
+	Effects int
+}{}
+
+func (c *Creature) StartRest(b Board, cs Creatures) bool {
+	/* StartRest begins resting, if no monster is in view.
+	   Returns "turn spent" marker, as first turn of rest is
+	   the same as waiting. */
+	if c.MonsterInView(b, cs) == true {
+		AddMessage("you can not rest with enemies in view.", MessageColorBad)
+		return false
+	}
+	Rest.Active = true
+	Rest.Turns = 0
+	Rest.HP = c.HPCurrent
+	Rest.Effects = len(c.Effects)
+	return true
+}
+
+func (c *Creature) ContinueRest(b Board, cs Creatures) bool {
+	/* ContinueRest checks if player should keep resting, and
+	   stops rest otherwise. Rest is interrupted when a monster
+	   comes into view, when HP or status effects of player change,
+	   or after RestMaxTurns turns. */
+	if Rest.Active == false {
+		return false
+	}
+	Rest.Turns++
+	if Rest.Turns >= RestMaxTurns || c.HPCurrent != Rest.HP ||
+		len(c.Effects) != Rest.Effects || c.MonsterInView(b, cs) == true {
+		Rest.Active = false
+	}
+	return Rest.Active
+}
+
+func (c *Creature) MonsterInView(b Board, cs Creatures) bool {
+	/* MonsterInView returns true if any living monster is
+	   in receiver's line of sight. */
+	for _, v := range cs {
+		if v == c || v.HPCurrent <= 0 || v.AIType == NoAI {
+			continue
 		}
-	case ExplosiveResource:
-		c.Explosive += RandRange(1, 3)
-		if c.Explosive > AmmoMax {
-			c.Explosive = AmmoMax
+		if c.CanSee(v.X, v.Y, b) == true {
+			return true
 		}
-	case KineticResource:
-		c.Kinetic += RandRange(1, 3)
-		if c.Kinetic > AmmoMax {
-			c.Kinetic = AmmoMax
+	}
+	return false
+}
+
+func (c *Creature) CanSee(tx, ty int, b Board) bool {
+	/* CanSee checks if line between receiver and tx, ty
+	   is not obscured by tiles that blocks sight. Blocked tiles
+	   are opaque as well - walls of older saves may not be marked
+	   as blocking sight.
+	   Tile at tx, ty itself is not checked. */
+	vec, err := NewVector(c.X, c.Y, tx, ty)
+	if err != nil {
+		return false
+	}
+	ComputeVector(vec)
+	for i := range vec.TilesX {
+		x, y := vec.TilesX[i], vec.TilesY[i]
+		if (x == c.X && y == c.Y) || (x == tx && y == ty) {
+			continue
 		}
-	case ElectromagneticResource:
-		c.Electromagnetic += RandRange(1, 3)
-		if c.Electromagnetic > AmmoMax {
-			c.Electromagnetic = AmmoMax
+		if b[x][y].BlocksSight == true || b[x][y].Blocked == true {
+			return false
 		}
 	}
+	return true
 }
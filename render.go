@@ -28,6 +28,7 @@ package main
 
 import (
 	blt "bearlibterminal"
+	"strconv"
 	"unicode/utf8"
 )
 
@@ -45,26 +46,15 @@ const (
 	ObjectsLayer
 	CreaturesLayer
 	PlayerLayer
+	EffectsLayer
+	IntentsLayer
+	OverlayLayer
 	LookLayer
 )
 
 const (
-	// Values for rendering resources on map.
-	// Icon is the unicode symbol;
-	// ColorGood is the base color of still active source of resource;
-	// ColorBad is color of already drained - therefore inactive - tile.
-	BallisticIcon            = "☉"
-	BallisticColorGood       = "crimson"
-	BallisticColorBad        = "darker crimson"
-	ExplosiveIcon            = "☄"
-	ExplosiveColorGood       = "flame"
-	ExplosiveColorBad        = "darker flame"
-	KineticIcon              = "☀"
-	KineticColorGood         = "amber"
-	KineticColorBad          = "darker amber"
-	ElectromagneticIcon      = "☇"
-	ElectromagneticColorGood = "cyan"
-	ElectromagneticColorBad  = "darker cyan"
+	// Pause that lets player notice that turn is lost.
+	StunnedDelay = 300
 )
 
 func PrintBoard(b Board, c Creatures) {
@@ -122,6 +112,70 @@ func PrintCreatures(b Board, c Creatures) {
 		}
 		SimplePutExt(v.X, v.Y, 0, 0, v.Char,
 			colors[0], colors[1], colors[2], colors[3])
+		if len(v.Effects) > 0 {
+			// The most recent effect is shown in the corner of tile.
+			e := v.Effects[len(v.Effects)-1]
+			blt.Layer(EffectsLayer)
+			blt.Print(v.X, v.Y, "[font=small][offset="+EffectIconOffset+"]"+
+				"[color="+e.Color+"]"+e.Icon+"[/color][/font]")
+		}
+	}
+}
+
+// Arrows that show direction of planned move, indexed by dx+1, dy+1.
+var IntentArrows = [3][3]string{
+	{"↖", "←", "↙"},
+	{"↑", " ", "↓"},
+	{"↗", "→", "↘"},
+}
+
+const (
+	// Glyphs and colors of monsters' intents.
+	IntentMeleeChar   = "×"
+	IntentMeleeColor  = "red"
+	IntentRangedChar  = "·"
+	IntentRangedColor = "dark red"
+)
+
+func PrintIntents(b Board, c Creatures) {
+	/* Function PrintIntents shows actions planned by monsters for their
+	   next turn, on separate layer:
+	   - arrow on the destination of planned move,
+	   - cross in the corner of tile targeted by melee attack,
+	   - dotted line along planned shot, and cross on its target.
+	   Arrows use monster's own color, to show which monster will move. */
+	blt.Layer(IntentsLayer)
+	for _, v := range c {
+		if v.HPCurrent <= 0 {
+			continue
+		}
+		switch v.Intent.Action {
+		case IntentMove:
+			arrow := IntentArrows[v.Intent.DX+1][v.Intent.DY+1]
+			blt.Print(v.X+v.Intent.DX, v.Y+v.Intent.DY,
+				"[color="+v.Color+"]"+arrow+"[/color]")
+		case IntentMelee:
+			blt.Print(v.Intent.X, v.Intent.Y, "[font=small][offset="+
+				EffectIconOffset+"][color="+IntentMeleeColor+"]"+
+				IntentMeleeChar+"[/color][/font]")
+		case IntentRanged:
+			vec := FireVector(v.X, v.Y, v.Intent.DX, v.Intent.DY, v.Range)
+			TraceVector(vec, b, c)
+			for i := 1; i < len(vec.TilesX); i++ {
+				x, y := vec.TilesX[i], vec.TilesY[i]
+				if b[x][y].Blocked == true {
+					break
+				}
+				if GetAliveCreatureFromTile(x, y, c) != nil {
+					blt.Print(x, y, "[font=small][offset="+
+						EffectIconOffset+"][color="+IntentMeleeColor+"]"+
+						IntentMeleeChar+"[/color][/font]")
+					break
+				}
+				blt.Print(x, y, "[color="+IntentRangedColor+"]"+
+					IntentRangedChar+"[/color]")
+			}
+		}
 	}
 }
 
@@ -134,15 +188,19 @@ func PrintUI(c *Creature) {
 	blt.Layer(UILayer)
 	const hpIconFull = "♦"
 	const hpIconEmpty = "♢"
-	hp := "[color=light blue]"
+	const hpColor = "light blue"
+	const hpHealedColor = "light green"
+	hp := ""
 	for i := 1; i <= c.HPMax; i++ {
 		if i > c.HPCurrent {
-			hp = hp + hpIconEmpty
+			hp = hp + "[color=" + hpColor + "]" + hpIconEmpty + "[/color]"
+		} else if i > c.HPCurrent-LastHealed {
+			// Recently restored HP are highlighted.
+			hp = hp + "[color=" + hpHealedColor + "]" + hpIconFull + "[/color]"
 		} else {
-			hp = hp + hpIconFull
+			hp = hp + "[color=" + hpColor + "]" + hpIconFull + "[/color]"
 		}
 	}
-	hp = hp + "[/color]"
 	blt.Print(UIPosX+1, UIPosY, hp)
 	const levelIcon = "■"
 	const levelColor = "darkest green"
@@ -158,60 +216,68 @@ func PrintUI(c *Creature) {
 		}
 		blt.Print(UIPosX+i-1+3, UIPosY+1, levelStr)
 	}
-	for y := 0; y < AmmoMax; y++ {
-		ballisticStr := ""
-		if y < c.Ballistic {
-			ballisticStr =
-				"[color=" + BallisticColorGood + "]" + BallisticIcon + "[/color]"
-		} else {
-			ballisticStr =
-				"[color=" + BallisticColorBad + "]" + BallisticIcon + "[/color]"
+	ammoRows := AmmoMax
+	for i, v := range DamageTypes {
+		if i >= SidebarSizeX {
+			break
 		}
-		blt.Print(MapSizeX, 1+y, ballisticStr)
-		explosiveStr := ""
-		if y < c.Explosive {
-			explosiveStr =
-				"[color=" + ExplosiveColorGood + "]" + ExplosiveIcon + "[/color]"
-		} else {
-			explosiveStr =
-				"[color=" + ExplosiveColorBad + "]" + ExplosiveIcon + "[/color]"
+		number := "[color=gray]" + strconv.Itoa(i+1) + "[/color]"
+		if i == c.Active {
+			number = "[color=white]" + strconv.Itoa(i+1) + "[/color]"
 		}
-		blt.Print(MapSizeX+1, 1+y, explosiveStr)
-		kineticStr := ""
-		if y < c.Kinetic {
-			kineticStr =
-				"[color=" + KineticColorGood + "]" + KineticIcon + "[/color]"
-		} else {
-			kineticStr =
-				"[color=" + KineticColorBad + "]" + KineticIcon + "[/color]"
+		blt.Print(MapSizeX+i, 0, number)
+		ammoCap := c.AmmoCap(v.Name)
+		if ammoCap > MapSizeY-1 {
+			ammoCap = MapSizeY - 1
 		}
-		blt.Print(MapSizeX, MapSizeY-2-y, kineticStr)
-		electromagneticStr := ""
-		if y < c.Electromagnetic {
-			electromagneticStr =
-				"[color=" + ElectromagneticColorGood + "]" +
-					ElectromagneticIcon + "[/color]"
-		} else {
-			electromagneticStr =
-				"[color=" + ElectromagneticColorBad + "]" +
-					ElectromagneticIcon + "[/color]"
+		if ammoCap > ammoRows {
+			ammoRows = ammoCap
+		}
+		for y := 0; y < ammoCap; y++ {
+			ammoStr := ""
+			if y < c.Ammo[v.Name] {
+				ammoStr = "[color=" + v.ColorGood + "]" + v.Icon + "[/color]"
+			} else {
+				ammoStr = "[color=" + v.ColorBad + "]" + v.Icon + "[/color]"
+			}
+			blt.Print(MapSizeX+i, 1+y, ammoStr)
 		}
-		blt.Print(MapSizeX+1, MapSizeY-2-y, electromagneticStr)
 	}
-	var numbersTemp = []string{"1", "2", "3", "4"}
-	var numbers = []string{}
-	for i, v := range numbersTemp {
-		if i == c.Active {
-			numbers = append(numbers, "[color=white]"+v+"[/color]")
+	for i := 0; i < InventorySlots; i++ {
+		// Inventory slots are shown under the sidebar.
+		slot := "[color=gray]" + strconv.Itoa(i+1) + "[/color]"
+		if i < len(c.Inventory) {
+			item := c.Inventory[i]
+			slot = slot + "[color=" + item.Color + "]" + item.Char + "[/color]"
 		} else {
-			numbers = append(numbers, "[color=gray]"+v+"[/color]")
+			slot = slot + "[color=darkest gray]·[/color]"
+		}
+		blt.Print(MapSizeX+i*2, UIPosY+1, slot)
+	}
+	for i, v := range c.Effects {
+		// Effects are listed below ammo, with remaining duration.
+		y := ammoRows + 2 + i
+		if y >= MapSizeY {
+			break
 		}
+		effectStr := "[color=" + v.Color + "]" + v.Icon + "[/color]" +
+			"[color=gray]" + strconv.Itoa(v.Duration) + "[/color]"
+		blt.Print(MapSizeX, y, effectStr)
 	}
-	blt.Print(MapSizeX, 0, numbers[0]+numbers[1])
-	blt.Print(MapSizeX, MapSizeY-1, numbers[2]+numbers[3])
 }
 
-func RenderAll(b Board, c Creatures) {
+func PrintObjects(b Board, o Objects) {
+	/* Function PrintObjects is used in RenderAll function.
+	   Takes map of level and slice of Objects as arguments,
+	   and prints every object on its layer. */
+	for _, v := range o {
+		blt.Layer(v.Layer)
+		color := blt.ColorFromName(v.Color)
+		SimplePutExt(v.X, v.Y, 0, 0, v.Char, color, color, color, color)
+	}
+}
+
+func RenderAll(b Board, o Objects, c Creatures) {
 	/* Function RenderAll prints every tile and character on game screen.
 	   Takes board slice (ie level map), slice of objects, and slice of creatures
 	   as arguments.
@@ -222,16 +288,30 @@ func RenderAll(b Board, c Creatures) {
 	   changes to the game window visible. */
 	blt.Clear()
 	PrintBoard(b, c)
+	PrintObjects(b, o)
 	PrintCreatures(b, c)
+	PrintIntents(b, c)
 	PrintUI((c)[0])
+	PrintLog()
+	PrintTargeting(b, c)
 	blt.Refresh()
 }
 
-func WinScreen() {
+func UpgradeScreen(offer []Upgrade) {
+	/* Function UpgradeScreen prints list of upgrades to choose from.
+	   Descriptions are printed in small font, as they may be long. */
 	blt.Clear()
-	txt := "You have won!"
-	blt.Print((WindowSizeX-utf8.RuneCountInString(txt))/2,
-		WindowSizeY/2, "You have won!")
+	blt.Layer(UILayer)
+	txt := "Choose upgrade"
+	blt.Print((WindowSizeX-utf8.RuneCountInString(txt))/2, 1, txt)
+	for i, v := range offer {
+		y := 3 + i*3
+		blt.Print(1, y, "[color=white]"+strconv.Itoa(i+1)+"[/color] "+v.Name)
+		desc := v.Description
+		if v.Kind == UpgradeAmmo {
+			desc = desc + " (" + v.DamageType + ")"
+		}
+		blt.Print(3, y+1, "[font=small][color=gray]"+desc+"[/color][/font]")
+	}
 	blt.Refresh()
-	blt.Read()
 }
@@ -28,6 +28,7 @@ package main
 
 import (
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"os"
 )
@@ -38,8 +39,26 @@ const (
 	MapPathGob       = "./" + MapNameGob
 	CreaturesNameGob = "monsters.gob"
 	CreaturesPathGob = "./" + CreaturesNameGob
+	ObjectsNameGob   = "objects.gob"
+	ObjectsPathGob   = "./" + ObjectsNameGob
+	RunNameGob       = "run.gob"
+	RunPathGob       = "./" + RunNameGob
 )
 
+type RunState struct {
+	/* RunState holds progress of the whole run: all generated levels,
+	   their monsters and objects, the current level number, and
+	   upgrade offer that is not chosen yet.
+	   Player (with upgrades) is stored in monsters save file. */
+	Seed             string
+	Kills            int
+	CurrentLevel     int
+	LevelMaps        []Board
+	CreaturesSpawned []Creatures
+	ObjectsSpawned   []Objects
+	PendingUpgrade   []Upgrade
+}
+
 func writeGob(path string, thing interface{}) error {
 	/* Function writeGob takes path-to-file, and any object (as interface{})
 	   as arguments, then encodes it to gob file. Returns error - unfortunately,
@@ -101,7 +120,66 @@ func loadCreatures(c *Creatures) error {
 	return err
 }
 
-func SaveGame(b Board, c Creatures) error {
+func saveObjects(o Objects) error {
+	/* Function saveObjects is helper function that takes objects
+	   as argument and encodes it to save file. */
+	err := writeGob(ObjectsPathGob, o)
+	return err
+}
+
+func loadObjects(o *Objects) error {
+	/* Function loadObjects is helper function that decodes saved data
+	   to slice of objects. Saves made before objects were introduced
+	   have no objects file - then map is just empty. */
+	if _, err := os.Stat(ObjectsPathGob); err != nil {
+		return nil
+	}
+	err := readGob(ObjectsPathGob, o)
+	return err
+}
+
+func saveRun() error {
+	/* Function saveRun is helper function that encodes progress
+	   of the run to save file. */
+	run := RunState{RunSeed, Kills, CurrentLevel, LevelMaps,
+		CreaturesSpawned, ObjectsSpawned, PendingUpgrade}
+	err := writeGob(RunPathGob, run)
+	return err
+}
+
+func loadRun(b *Board, o *Objects, c *Creatures) error {
+	/* Function loadRun is helper function that decodes progress
+	   of the run. Gob does not preserve pointers shared between
+	   saved values, so the current level is linked again with
+	   already loaded game map, objects and monsters. */
+	var run RunState
+	err := readGob(RunPathGob, &run)
+	if err != nil {
+		return err
+	}
+	RunSeed = run.Seed
+	Kills = run.Kills
+	CurrentLevel = run.CurrentLevel
+	OldLevel = run.CurrentLevel
+	LevelMaps = run.LevelMaps
+	CreaturesSpawned = run.CreaturesSpawned
+	ObjectsSpawned = run.ObjectsSpawned
+	PendingUpgrade = run.PendingUpgrade
+	if CurrentLevel < 1 || CurrentLevel > len(LevelMaps) ||
+		CurrentLevel > len(CreaturesSpawned) || len(*c) == 0 {
+		txt := RunLevelError(CurrentLevel, len(LevelMaps))
+		return errors.New("Saved run does not match saved level." + txt)
+	}
+	LevelMaps[CurrentLevel-1] = *b
+	CreaturesSpawned[CurrentLevel-1] = append(Creatures{}, (*c)[1:]...)
+	for len(ObjectsSpawned) < len(LevelMaps) {
+		ObjectsSpawned = append(ObjectsSpawned, Objects{})
+	}
+	ObjectsSpawned[CurrentLevel-1] = *o
+	return err
+}
+
+func SaveGame(b Board, o Objects, c Creatures) error {
 	/* Function SaveGame encodes game map, monsters, objects into
 	   save files, using Go's gob format. This function may need better
 	   error handling - it relies on gob's built-in errors that are
@@ -111,27 +189,41 @@ func SaveGame(b Board, c Creatures) error {
 	if err != nil {
 		fmt.Println(err)
 	}
+	err = saveObjects(o)
+	if err != nil {
+		fmt.Println(err)
+	}
 	err = saveCreatures(c)
 	if err != nil {
 		fmt.Println(err)
 	}
+	err = saveRun()
+	if err != nil {
+		fmt.Println(err)
+	}
 	return err
 }
 
-func LoadGame(b *Board, c *Creatures) error {
+func LoadGame(b *Board, o *Objects, c *Creatures) error {
 	/* Function LoadGame decoded save files (their names and paths are
 	   specified as constants on the top of this file) into
 	   game map, monsters and objects. As SaveGame, it may need
-	   better error handling due to unhelpful gob's error messages. */
+	   better error handling due to unhelpful gob's error messages.
+	   Returns the first encountered error. */
 	var err error
 	err = loadBoard(b)
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+	err = loadObjects(o)
+	if err != nil {
+		return err
 	}
 	err = loadCreatures(c)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
+	err = loadRun(b, o, c)
 	return err
 }
 
@@ -148,4 +240,12 @@ func DeleteSaves() {
 	if err == nil {
 		os.Remove(CreaturesPathGob)
 	}
+	_, err = os.Stat(ObjectsPathGob)
+	if err == nil {
+		os.Remove(ObjectsPathGob)
+	}
+	_, err = os.Stat(RunPathGob)
+	if err == nil {
+		os.Remove(RunPathGob)
+	}
 }
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+const (
+	/* Values for energy-based turn scheduler.
+	   Every turn, creatures gain energy equal to their speed;
+	   every action costs ActionCost energy. Creature with NormalSpeed
+	   acts once per turn, creature with speed 2*NormalSpeed acts twice,
+	   and creature with speed NormalSpeed/2 acts every other turn. */
+	ActionCost  = 100
+	NormalSpeed = 100
+)
+
+func (c *Creature) CurrentSpeed() int {
+	/* CurrentSpeed returns speed of receiver, modified by status
+	   effects. Speed set to 0 (ie not specified in json file)
+	   is treated as NormalSpeed. */
+	speed := c.Speed
+	if speed == 0 {
+		speed = NormalSpeed
+	}
+	multiplier := 1.0
+	for _, v := range c.Effects {
+		if v.SpeedMultiplier > 0 {
+			multiplier *= v.SpeedMultiplier
+		}
+	}
+	return int(float64(speed) * multiplier)
+}
+
+func (c *Creature) nextEnergyGain(p *Creature) int {
+	/* nextEnergyGain returns energy that receiver will gain during
+	   the next player's turn. Creatures with SpeedVariance have their
+	   speed rolled every turn; roll is stored, so planning and
+	   the turn itself use the same value. As game is turn-based from
+	   player's perspective, gain is scaled by player's speed - if player
+	   is slowed, everything else seems faster. */
+	if c.SpeedRolled == false {
+		c.SpeedRoll = 0
+		if c.SpeedVariance > 0 {
+			c.SpeedRoll = RandRange(-c.SpeedVariance, c.SpeedVariance)
+		}
+		c.SpeedRolled = true
+	}
+	speed := c.CurrentSpeed() + c.SpeedRoll
+	if speed < 0 {
+		speed = 0
+	}
+	playerSpeed := p.CurrentSpeed()
+	if playerSpeed <= 0 {
+		playerSpeed = NormalSpeed
+	}
+	return speed * NormalSpeed / playerSpeed
+}
+
+func (c *Creature) EnergyGain(p *Creature) int {
+	/* EnergyGain returns energy gained by receiver during this turn,
+	   and consumes speed roll, so the next turn gets new one. */
+	gain := c.nextEnergyGain(p)
+	c.SpeedRolled = false
+	return gain
+}
+
+func NextActor(cs Creatures) *Creature {
+	/* Function NextActor returns creature that should act now, or nil
+	   if nobody has enough energy. Turn order is deterministic and
+	   independent of order of Creatures slice: creature with more
+	   energy goes first, then faster one, then the one that is
+	   closer to the top-left corner of map. */
+	var next *Creature
+	for _, v := range cs {
+		if v.HPCurrent <= 0 || v.Energy < ActionCost {
+			continue
+		}
+		if next == nil || actsBefore(v, next) == true {
+			next = v
+		}
+	}
+	return next
+}
+
+func actsBefore(c, other *Creature) bool {
+	/* Function actsBefore compares two creatures, and returns true
+	   if "c" should act before "other". Living creatures never share
+	   tile, so coords are always enough to break ties. */
+	if c.Energy != other.Energy {
+		return c.Energy > other.Energy
+	}
+	if c.CurrentSpeed() != other.CurrentSpeed() {
+		return c.CurrentSpeed() > other.CurrentSpeed()
+	}
+	if c.Y != other.Y {
+		return c.Y < other.Y
+	}
+	return c.X < other.X
+}
+
+func (c *Creature) WillActNextTurn(p *Creature) bool {
+	/* WillActNextTurn checks if receiver will have enough energy
+	   to act during the next turn. Speed variance is rolled here
+	   already, and the same roll is used by EnergyGain. */
+	return c.Energy+c.nextEnergyGain(p) >= ActionCost
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import "testing"
+
+func testActor(name string, energy, speed, x, y, hp int) *Creature {
+	c := &Creature{}
+	c.Name, c.Energy, c.Speed, c.X, c.Y, c.HPCurrent = name, energy,
+		speed, x, y, hp
+	return c
+}
+
+func TestNextActor(t *testing.T) {
+	var tests = []struct {
+		name   string
+		actors Creatures
+		want   string
+	}{
+		{"nobody is ready", Creatures{
+			testActor("a", ActionCost-1, 0, 0, 0, 1)}, ""},
+		{"more energy first", Creatures{
+			testActor("a", ActionCost, 0, 0, 0, 1),
+			testActor("b", ActionCost+10, 0, 5, 5, 1)}, "b"},
+		{"then faster", Creatures{
+			testActor("a", ActionCost, NormalSpeed, 0, 0, 1),
+			testActor("b", ActionCost, NormalSpeed*2, 5, 5, 1)}, "b"},
+		{"then upper row", Creatures{
+			testActor("a", ActionCost, 0, 0, 2, 1),
+			testActor("b", ActionCost, 0, 5, 1, 1)}, "b"},
+		{"then left column", Creatures{
+			testActor("a", ActionCost, 0, 3, 1, 1),
+			testActor("b", ActionCost, 0, 2, 1, 1)}, "b"},
+		{"dead do not act", Creatures{
+			testActor("a", ActionCost, 0, 0, 0, 1),
+			testActor("b", ActionCost*2, 0, 1, 1, 0)}, "a"},
+	}
+	for _, tt := range tests {
+		/* Order of Creatures slice must not matter. */
+		reversed := Creatures{}
+		for i := len(tt.actors) - 1; i >= 0; i-- {
+			reversed = append(reversed, tt.actors[i])
+		}
+		for _, cs := range []Creatures{tt.actors, reversed} {
+			got := ""
+			if c := NextActor(cs); c != nil {
+				got = c.Name
+			}
+			if got != tt.want {
+				t.Errorf("%s: NextActor = %q, want %q", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCurrentSpeed(t *testing.T) {
+	var tests = []struct {
+		name   string
+		speed  int
+		slowed bool
+		want   int
+	}{
+		{"default speed", 0, false, NormalSpeed},
+		{"fast", NormalSpeed * 2, false, NormalSpeed * 2},
+		{"slowed", NormalSpeed, true, NormalSpeed / 2},
+		{"slowed default speed", 0, true, NormalSpeed / 2},
+	}
+	for _, tt := range tests {
+		c := testActor("a", 0, tt.speed, 0, 0, 1)
+		if tt.slowed == true {
+			c.ApplyEffect(EffectSlowed, 0)
+		}
+		if got := c.CurrentSpeed(); got != tt.want {
+			t.Errorf("%s: CurrentSpeed = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnergyGain(t *testing.T) {
+	/* Gain is scaled by player's speed, so slowed player
+	   makes everything else faster. */
+	var tests = []struct {
+		name         string
+		speed        int
+		slowed       bool
+		playerSlowed bool
+		want         int
+	}{
+		{"normal", NormalSpeed, false, false, NormalSpeed},
+		{"slowed", NormalSpeed, true, false, NormalSpeed / 2},
+		{"player slowed", NormalSpeed, false, true, NormalSpeed * 2},
+		{"both slowed", NormalSpeed, true, true, NormalSpeed},
+		{"fast, player slowed", NormalSpeed * 2, false, true, NormalSpeed * 4},
+	}
+	for _, tt := range tests {
+		p := testActor("player", 0, NormalSpeed, 0, 0, 1)
+		c := testActor("a", 0, tt.speed, 1, 1, 1)
+		if tt.slowed == true {
+			c.ApplyEffect(EffectSlowed, 0)
+		}
+		if tt.playerSlowed == true {
+			p.ApplyEffect(EffectSlowed, 0)
+		}
+		if got := c.EnergyGain(p); got != tt.want {
+			t.Errorf("%s: EnergyGain = %d, want %d", tt.name, got, tt.want)
+		}
+		if c.SpeedRolled == true {
+			t.Errorf("%s: speed roll is not consumed", tt.name)
+		}
+	}
+}
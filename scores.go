@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"os"
+	"sort"
+)
+
+const (
+	// Number of records kept in high scores file.
+	ScoresMax = 10
+	// Points for reaching level, killing monster, and winning.
+	ScoreLevel   = 100
+	ScoreKill    = 10
+	ScoreVictory = 1000
+)
+
+type Score struct {
+	/* Score is single record of high scores table. */
+	Seed   string
+	Level  int
+	Kills  int
+	Won    bool
+	Points int
+}
+
+// Scores is high scores table, sorted from the best one.
+type Scores []Score
+
+func NewScore(won bool) Score {
+	/* Function NewScore creates record of the current run. */
+	points := CurrentLevel*ScoreLevel + Kills*ScoreKill
+	if won == true {
+		points += ScoreVictory
+	}
+	return Score{RunSeed, CurrentLevel, Kills, won, points}
+}
+
+func LoadScores() (Scores, error) {
+	/* Function LoadScores reads high scores table. Missing file
+	   means that there are no scores yet; file that exists, but
+	   can not be read, returns error. */
+	var scores = Scores{}
+	if _, err := os.Stat(ScoresPathJson); err != nil {
+		return scores, nil
+	}
+	err := ScoresFromJson(ScoresPathJson, &scores)
+	if err != nil {
+		return Scores{}, err
+	}
+	return scores, nil
+}
+
+func AddScore(s Score) error {
+	/* Function AddScore puts new record to high scores table,
+	   then keeps only ScoresMax best records. Unreadable scores
+	   file is not overwritten - error is returned instead. */
+	scores, err := LoadScores()
+	if err != nil {
+		return err
+	}
+	scores = append(scores, s)
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Points > scores[j].Points
+	})
+	if len(scores) > ScoresMax {
+		scores = scores[:ScoresMax]
+	}
+	return ScoresToJson(ScoresPathJson, scores)
+}
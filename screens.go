@@ -0,0 +1,369 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	blt "bearlibterminal"
+)
+
+type Screen interface {
+	/* Screen is single state of game flow - menu, game itself,
+	   death screen, etc. Update renders screen and handles
+	   one step of input; it may push, pop, or replace screens. */
+	Update()
+}
+
+// ScreenStack holds all open screens; the last one is active.
+type ScreenStack []Screen
+
+var Screens = ScreenStack{}
+
+func (s *ScreenStack) Push(sc Screen) {
+	/* Push opens new screen on top of the stack. */
+	*s = append(*s, sc)
+}
+
+func (s *ScreenStack) Pop() {
+	/* Pop closes active screen. */
+	if len(*s) > 0 {
+		(*s)[len(*s)-1] = nil
+		*s = (*s)[:len(*s)-1]
+	}
+}
+
+func (s *ScreenStack) Replace(sc Screen) {
+	/* Replace closes active screen, and opens new one in its place. */
+	s.Pop()
+	s.Push(sc)
+}
+
+func (s *ScreenStack) Clear() {
+	/* Clear closes all screens; it ends the game. */
+	for len(*s) > 0 {
+		s.Pop()
+	}
+}
+
+func (s ScreenStack) Top() Screen {
+	/* Top returns active screen, or nil if stack is empty. */
+	if len(s) == 0 {
+		return nil
+	}
+	return s[len(s)-1]
+}
+
+func RunScreens() {
+	/* Function RunScreens is the main loop of the game:
+	   it updates active screen until all screens are closed. */
+	for {
+		sc := Screens.Top()
+		if sc == nil {
+			break
+		}
+		sc.Update()
+	}
+}
+
+func PrintCentered(y int, txt string) {
+	/* Function PrintCentered prints text (may contain BLT markup)
+	   in the middle of the row. */
+	blt.Print((WindowSizeX-RuneCountInBltString(txt))/2, y, txt)
+}
+
+const (
+	// Main menu entries.
+	MenuNewGame  = "New Game"
+	MenuContinue = "Continue"
+	MenuDelete   = "Delete Save"
+	MenuOptions  = "Options"
+	MenuScores   = "Scores"
+	MenuHelp     = "Help"
+	MenuQuit     = "Quit"
+)
+
+type MenuScreen struct {
+	/* MenuScreen is title screen of the game. LoadErr is set if
+	   saved game could not be loaded - then save is corrupted,
+	   even if all save files are present. */
+	Menu    ListMenu
+	LoadErr error
+}
+
+func (m *MenuScreen) Entries(save int) []string {
+	/* Entries returns menu entries; Continue is available only
+	   if game is saved, and corrupted save may be only deleted. */
+	var entries = []string{MenuNewGame}
+	switch save {
+	case SaveValid:
+		entries = append(entries, MenuContinue)
+	case SaveCorrupted:
+		entries = append(entries, MenuDelete)
+	}
+	return append(entries, MenuOptions, MenuScores, MenuHelp, MenuQuit)
+}
+
+func (m *MenuScreen) Update() {
+	save, saveErr := CheckSave()
+	if save == SaveValid && m.LoadErr != nil {
+		save, saveErr = SaveCorrupted, m.LoadErr
+	}
+	m.Menu.Items = m.Entries(save)
+	m.Menu.X, m.Menu.Y, m.Menu.W = 0, 4, WindowSizeX
+	m.Menu.Spacing, m.Menu.Centered = 2, true
+	blt.Clear()
+	blt.Layer(UILayer)
+	PrintCentered(1, "[color=white]"+GameTitle+"[/color]")
+	PrintCentered(2, "[color=gray]"+GameVersion+"[/color]")
+	m.Menu.Draw()
+	if save == SaveCorrupted {
+		lines := WrapText(saveErr.Error(), WindowSizeX-2)
+		for i, v := range lines {
+			PrintCentered(WindowSizeY-1-len(lines)+i,
+				"[color=light red]"+EscapeBltString(v)+"[/color]")
+		}
+	}
+	blt.Refresh()
+	key := ReadInput()
+	if key == blt.TK_ESCAPE || key == blt.TK_CLOSE {
+		Screens.Clear()
+		return
+	}
+	if m.Menu.HandleKey(key) == false {
+		return
+	}
+	switch m.Menu.Items[m.Menu.Cursor] {
+	case MenuNewGame:
+		if save != SaveValid ||
+			Confirm("Start new game? Saved game will be lost.") == true {
+			m.LoadErr = nil
+			Screens.Push(&SeedScreen{})
+		}
+	case MenuContinue:
+		g, err := ContinueGame()
+		if err != nil {
+			m.LoadErr = err
+			return
+		}
+		Screens.Push(g)
+	case MenuDelete:
+		if Confirm("Saved game is corrupted, and can not be continued. Delete it?") == true {
+			DeleteSaves()
+			m.LoadErr = nil
+		}
+	case MenuOptions:
+		Screens.Push(&OptionsScreen{})
+	case MenuScores:
+		Screens.Push(&ScoresScreen{})
+	case MenuHelp:
+		Screens.Push(&HelpScreen{})
+	case MenuQuit:
+		Screens.Clear()
+	}
+}
+
+type SeedScreen struct {
+	/* SeedScreen asks for seed of the new game. Empty seed
+	   means random one. */
+	Input TextInput
+}
+
+// Maximal length of seed typed by player.
+const SeedMaxLength = WindowSizeX - 2
+
+func (s *SeedScreen) Update() {
+	s.Input.X, s.Input.Y, s.Input.MaxLength = 1, 4, SeedMaxLength
+	blt.Clear()
+	blt.Layer(UILayer)
+	PrintCentered(2, "[color=white]Seed:[/color]")
+	s.Input.Draw()
+	PrintHint(6, "Empty: random seed")
+	PrintHint(7, "Enter: start; Esc: back")
+	blt.Refresh()
+	key := blt.Read()
+	done, cancelled := s.Input.HandleKey(key)
+	if done == true {
+		Screens.Replace(StartNewGame(s.Input.Text))
+	} else if key == blt.TK_CLOSE {
+		Screens.Clear()
+	} else if cancelled == true {
+		Screens.Pop()
+	}
+}
+
+type OptionsScreen struct {
+	/* OptionsScreen allows to change options for the current session.
+	   Changes are written to options_controls.cfg only if saved
+	   in BindingsScreen. */
+	Menu ListMenu
+}
+
+func (o *OptionsScreen) Update() {
+	o.Menu.Items = []string{"Layout", "Custom keys", "Targeting", "Animations",
+		"Key bindings"}
+	o.Menu.Values = []string{LayoutNames[KeyboardLayout],
+		strconv.FormatBool(CustomControls),
+		strconv.FormatBool(TargetingPreview),
+		AnimationNames[AnimationSpeed], ""}
+	o.Menu.X, o.Menu.Y, o.Menu.W, o.Menu.Spacing = 1, 3, WindowSizeX-2, 2
+	blt.Clear()
+	blt.Layer(UILayer)
+	PrintCentered(1, "[color=white]Options[/color]")
+	o.Menu.Draw()
+	PrintHint(WindowSizeY-2, "Enter: change; Esc: back")
+	blt.Refresh()
+	key := ReadInput()
+	switch key {
+	case blt.TK_ESCAPE:
+		Screens.Pop()
+		return
+	case blt.TK_CLOSE:
+		Screens.Clear()
+		return
+	case blt.TK_RIGHT, blt.TK_KP_6:
+		key = blt.TK_RETURN
+	}
+	if o.Menu.HandleKey(key) == false {
+		return
+	}
+	switch o.Menu.Cursor {
+	case 0:
+		KeyboardLayout = (KeyboardLayout + 1) % len(LayoutNames)
+		ChooseKeyboardLayout()
+	case 1:
+		CustomControls = !CustomControls
+	case 2:
+		TargetingPreview = !TargetingPreview
+	case 3:
+		AnimationSpeed = (AnimationSpeed + 1) % len(AnimationNames)
+	case 4:
+		Screens.Push(&BindingsScreen{})
+	}
+}
+
+func UpdatePaneScreen(pane *TextPane, title string) {
+	/* Function UpdatePaneScreen draws framed, scrollable text,
+	   and handles one key. It is shared by screens that only
+	   show text, like scores or help. */
+	blt.Clear()
+	DrawBox(0, 0, WindowSizeX, WindowSizeY-1, title)
+	pane.Draw()
+	PrintHint(WindowSizeY-1, "Esc: back")
+	blt.Refresh()
+	key := ReadInput()
+	if key == blt.TK_CLOSE {
+		Screens.Clear()
+	} else if key == blt.TK_ESCAPE || key == blt.TK_RETURN {
+		Screens.Pop()
+	} else {
+		pane.HandleKey(key)
+	}
+}
+
+func NewFramedPane() *TextPane {
+	/* Function NewFramedPane returns empty TextPane that fits in
+	   box drawn by UpdatePaneScreen. */
+	return &TextPane{X: 1, Y: 1, W: WindowSizeX - 2, H: WindowSizeY - 3}
+}
+
+type ScoresScreen struct {
+	/* ScoresScreen shows high scores table. */
+	Pane *TextPane
+}
+
+func (s *ScoresScreen) Update() {
+	if s.Pane == nil {
+		s.Pane = NewFramedPane()
+		scores, err := LoadScores()
+		if err != nil {
+			s.Pane.AddLine("Scores file can not be read.", "light red")
+		} else if len(scores) == 0 {
+			s.Pane.AddLine("No scores yet.", "gray")
+		}
+		for i, v := range scores {
+			color := "gray"
+			if v.Won == true {
+				color = "light green"
+			}
+			txt := strconv.Itoa(i+1) + ". " + strconv.Itoa(v.Points) +
+				" L" + strconv.Itoa(v.Level) + " " + v.Seed
+			if utf8.RuneCountInString(txt) > s.Pane.W {
+				txt = string([]rune(txt)[:s.Pane.W])
+			}
+			s.Pane.Lines = append(s.Pane.Lines, txt)
+			s.Pane.Colors = append(s.Pane.Colors, color)
+		}
+	}
+	UpdatePaneScreen(s.Pane, "Scores")
+}
+
+type HelpScreen struct {
+	/* HelpScreen lists all actions with keys bound to them
+	   in the current controls scheme. */
+	Pane *TextPane
+}
+
+func (h *HelpScreen) Update() {
+	if h.Pane == nil {
+		h.Pane = NewFramedPane()
+		for _, v := range Actions {
+			h.Pane.AddLine(v+": "+KeyNames(v), "white")
+		}
+		h.Pane.AddLine("Shift+S: save and quit", "gray")
+		h.Pane.AddLine("Shift+Q: abandon run", "gray")
+	}
+	UpdatePaneScreen(h.Pane, "Help")
+}
+
+type EndScreen struct {
+	/* EndScreen is shown after death or victory; it shows score
+	   of the run, then returns to main menu. */
+	Won   bool
+	Score Score
+}
+
+func (e *EndScreen) Update() {
+	blt.Clear()
+	blt.Layer(UILayer)
+	if e.Won == true {
+		PrintCentered(WindowSizeY/2-2, "[color=light green]You have won![/color]")
+	} else {
+		PrintCentered(WindowSizeY/2-2, "[color=light red]You have died.[/color]")
+	}
+	PrintCentered(WindowSizeY/2, "Level "+strconv.Itoa(e.Score.Level)+
+		", kills "+strconv.Itoa(e.Score.Kills))
+	PrintCentered(WindowSizeY/2+1, "Score: "+strconv.Itoa(e.Score.Points))
+	blt.Refresh()
+	if key := ReadInput(); key == blt.TK_CLOSE {
+		Screens.Clear()
+	} else if key == blt.TK_ESCAPE || key == blt.TK_RETURN ||
+		key == blt.TK_SPACE {
+		Screens.Pop()
+	}
+}
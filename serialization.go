@@ -33,8 +33,16 @@ import (
 
 const (
 	// Constant values for data files manipulation.
-	CreaturesPathJson = "./data/monsters/"
-	MapsPathJson      = "./data/maps/"
+	CreaturesPathJson     = "./data/monsters/"
+	MapsPathJson          = "./data/maps/"
+	ObjectsPathJson       = "./data/objects/"
+	SpawnTablePathJson    = "./data/spawns/spawn_table.json"
+	DamageTypesPathJson   = "./data/damage/damage_types.json"
+	StatusEffectsPathJson = "./data/effects/effects.json"
+	HealingPathJson       = "./data/healing/healing.json"
+	UpgradesPathJson      = "./data/upgrades/upgrades.json"
+	LayoutsPathJson       = "./data/layouts/"
+	ScoresPathJson        = "./scores.json"
 )
 
 func writeJson(path string, thing interface{}) error {
@@ -74,3 +82,65 @@ func CreatureFromJson(path string, c *Creature) error {
 	err := readJson(path, c)
 	return err
 }
+
+func SpawnTableFromJson(path string, t *SpawnTable) error {
+	/* Function SpawnTableFromJson decodes spawn table json file
+	   into SpawnTable passed as argument. */
+	err := readJson(path, t)
+	return err
+}
+
+func DamageTypesFromJson(path string, dts *[]DamageType) error {
+	/* Function DamageTypesFromJson decodes damage types json file
+	   into slice of DamageType passed as argument. */
+	err := readJson(path, dts)
+	return err
+}
+
+func StatusEffectsFromJson(path string, effects *[]StatusEffect) error {
+	/* Function StatusEffectsFromJson decodes effects json file
+	   into slice of StatusEffect passed as argument. */
+	err := readJson(path, effects)
+	return err
+}
+
+func HealingFromJson(path string, h *HealingOptions) error {
+	/* Function HealingFromJson decodes healing json file
+	   into HealingOptions passed as argument. */
+	err := readJson(path, h)
+	return err
+}
+
+func UpgradesFromJson(path string, upgrades *[]Upgrade) error {
+	/* Function UpgradesFromJson decodes upgrades json file
+	   into slice of Upgrade passed as argument. */
+	err := readJson(path, upgrades)
+	return err
+}
+
+func LayoutFromJson(path string, l *KeyboardLayoutData) error {
+	/* Function LayoutFromJson decodes keyboard layout json file
+	   into KeyboardLayoutData passed as argument. */
+	err := readJson(path, l)
+	return err
+}
+
+func ObjectFromJson(path string, o *Object) error {
+	/* Function ObjectFromJson decodes object json file
+	   into Object passed as argument. */
+	err := readJson(path, o)
+	return err
+}
+
+func ScoresFromJson(path string, s *Scores) error {
+	/* Function ScoresFromJson decodes high scores json file
+	   into Scores passed as argument. */
+	err := readJson(path, s)
+	return err
+}
+
+func ScoresToJson(path string, s Scores) error {
+	/* Function ScoresToJson encodes high scores into json file. */
+	err := writeJson(path, s)
+	return err
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+type SpawnEntry struct {
+	/* SpawnEntry is single record of spawn table.
+	   Monster is name of json file stored in data/monsters,
+	   Weight is relative chance of being picked,
+	   and Threat is value deducted from level's threat budget
+	   every time this monster is spawned. */
+	Monster string
+	Weight  int
+	Threat  int
+}
+
+type ObjectEntry struct {
+	/* ObjectEntry is record of objects part of spawn table.
+	   Object is name of json file stored in data/objects,
+	   Weight is relative chance of being picked. */
+	Object string
+	Weight int
+}
+
+type LevelSpawns struct {
+	/* LevelSpawns describes monsters population of one level:
+	   how many monsters should be spawned, how much threat in total
+	   level may hold, and which monsters may appear there.
+	   Threat budget has priority over MonstersMin - spawning stops
+	   as soon as no monster fits in remaining budget.
+	   Objects lying on the level are described the same way,
+	   but without threat. */
+	MonstersMin  int
+	MonstersMax  int
+	ThreatBudget int
+	Monsters     []SpawnEntry
+	ObjectsMin   int
+	ObjectsMax   int
+	Objects      []ObjectEntry
+}
+
+// SpawnTable holds LevelSpawns of all levels; index 0 is the first level.
+type SpawnTable []LevelSpawns
+
+/* Spawns is spawn table read at the start of the game
+   (see InitializeSpawnTable). */
+var Spawns = SpawnTable{}
+
+func InitializeSpawnTable() {
+	/* Function InitializeSpawnTable reads and validates spawn table
+	   at the start of the game. Level generator can not work with
+	   empty or malformed spawn table, so every error is fatal. */
+	table, err := NewSpawnTable()
+	if err != nil {
+		fmt.Println(err)
+		panic(-1)
+	}
+	Spawns = table
+}
+
+func NewSpawnTable() (SpawnTable, error) {
+	/* NewSpawnTable reads spawn table from json file, then checks
+	   if every level description makes sense.
+	   Errors returned by json package are not very helpful, and
+	   hard to work with, so there is lazy panic for them - the same as
+	   in NewCreature. */
+	var table = SpawnTable{}
+	err := SpawnTableFromJson(SpawnTablePathJson, &table)
+	if err != nil {
+		panic(err)
+	}
+	var err2 error
+	if len(table) == 0 {
+		err2 = errors.New("Spawn table is empty.")
+	}
+	for i, level := range table {
+		if level.MonstersMin < 0 || level.MonstersMin > level.MonstersMax {
+			txt := SpawnRangeError(i, level.MonstersMin, level.MonstersMax)
+			err2 = errors.New("Spawn table has invalid monsters range." + txt)
+		}
+		for _, v := range level.Monsters {
+			if v.Weight <= 0 || v.Threat < 0 {
+				txt := SpawnEntryError(i, v.Monster, v.Weight, v.Threat)
+				err2 = errors.New("Spawn table entry has invalid weight or threat." + txt)
+			}
+		}
+		if level.ObjectsMin < 0 || level.ObjectsMin > level.ObjectsMax {
+			txt := SpawnRangeError(i, level.ObjectsMin, level.ObjectsMax)
+			err2 = errors.New("Spawn table has invalid objects range." + txt)
+		}
+		for _, v := range level.Objects {
+			if v.Weight <= 0 {
+				txt := SpawnEntryError(i, v.Object, v.Weight, 0)
+				err2 = errors.New("Spawn table entry has invalid weight." + txt)
+			}
+		}
+	}
+	return table, err2
+}
+
+func (t SpawnTable) ForLevel(level int) LevelSpawns {
+	/* ForLevel returns LevelSpawns for level passed as argument
+	   (counting from 0). If spawn table is shorter than number
+	   of levels, the last defined level is reused, so designers
+	   do not need to fill the table for every level.
+	   Empty table returns empty LevelSpawns. */
+	if len(t) == 0 {
+		return LevelSpawns{}
+	}
+	if level >= len(t) {
+		level = len(t) - 1
+	}
+	return t[level]
+}
+
+func (l LevelSpawns) PickMonster(budget int) (SpawnEntry, bool) {
+	/* PickMonster chooses one of level's monsters, with respect to
+	   their weights. Only monsters that are not more threatening than
+	   budget are taken into account. Returns false if none of monsters
+	   fits in budget. */
+	var entries = []SpawnEntry{}
+	sum := 0
+	for _, v := range l.Monsters {
+		if v.Threat <= budget && v.Weight > 0 {
+			entries = append(entries, v)
+			sum += v.Weight
+		}
+	}
+	if sum == 0 {
+		return SpawnEntry{}, false
+	}
+	roll := rand.Intn(sum)
+	for _, v := range entries {
+		if roll < v.Weight {
+			return v, true
+		}
+		roll -= v.Weight
+	}
+	return entries[len(entries)-1], true
+}
+
+func (l LevelSpawns) PickObject() (ObjectEntry, bool) {
+	/* PickObject chooses one of level's objects, with respect to
+	   their weights. Returns false if level has no objects defined. */
+	sum := 0
+	for _, v := range l.Objects {
+		if v.Weight > 0 {
+			sum += v.Weight
+		}
+	}
+	if sum == 0 {
+		return ObjectEntry{}, false
+	}
+	roll := rand.Intn(sum)
+	for _, v := range l.Objects {
+		if v.Weight <= 0 {
+			continue
+		}
+		if roll < v.Weight {
+			return v, true
+		}
+		roll -= v.Weight
+	}
+	return l.Objects[len(l.Objects)-1], true
+}
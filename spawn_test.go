@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import "testing"
+
+func TestPickMonster(t *testing.T) {
+	var level = LevelSpawns{Monsters: []SpawnEntry{
+		{"small.json", 3, 1},
+		{"big.json", 1, 5},
+		{"never.json", 0, 0},
+	}}
+	var tests = []struct {
+		name    string
+		level   LevelSpawns
+		budget  int
+		wantOK  bool
+		allowed []string
+	}{
+		{"whole budget", level, 5, true, []string{"small.json", "big.json"}},
+		{"budget excludes big", level, 4, true, []string{"small.json"}},
+		{"nothing fits", level, 0, false, nil},
+		{"no monsters", LevelSpawns{}, 10, false, nil},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			entry, ok := tt.level.PickMonster(tt.budget)
+			if ok != tt.wantOK {
+				t.Fatalf("%s: ok = %v, want %v", tt.name, ok, tt.wantOK)
+			}
+			if ok == false {
+				continue
+			}
+			found := false
+			for _, v := range tt.allowed {
+				if entry.Monster == v {
+					found = true
+				}
+			}
+			if found == false {
+				t.Fatalf("%s: picked %s, want one of %v", tt.name,
+					entry.Monster, tt.allowed)
+			}
+		}
+	}
+}
+
+func TestPickMonsterWeights(t *testing.T) {
+	/* Weights are relative chances: with 3:1 weights, the first
+	   monster should be picked about three times out of four.
+	   Margin is wide enough to never fail by chance. */
+	var level = LevelSpawns{Monsters: []SpawnEntry{
+		{"small.json", 3, 1},
+		{"big.json", 1, 1},
+	}}
+	const picks = 10000
+	small := 0
+	for i := 0; i < picks; i++ {
+		entry, _ := level.PickMonster(1)
+		if entry.Monster == "small.json" {
+			small++
+		}
+	}
+	if ratio := float64(small) / picks; ratio < 0.72 || ratio > 0.78 {
+		t.Errorf("small.json picked %.3f of times, want about 0.75", ratio)
+	}
+}
+
+func TestForLevel(t *testing.T) {
+	var table = SpawnTable{{MonstersMax: 1}, {MonstersMax: 2}}
+	var tests = []struct {
+		table SpawnTable
+		level int
+		want  int
+	}{
+		{table, 0, 1},
+		{table, 1, 2},
+		{table, 4, 2},
+		{SpawnTable{}, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.table.ForLevel(tt.level).MonstersMax; got != tt.want {
+			t.Errorf("ForLevel(%d).MonstersMax = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
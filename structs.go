@@ -62,18 +62,55 @@ type FighterProperties struct {
 	   elements as well.
 	   AI types are iota (integers) defined
 	   in creatures.go.
-	   Active is the currently selected weapon.
-	   Ballistic and the next ones: ramaining ammunition. */
-	AIType          int
-	AITriggered     bool
-	HPMax           int
-	HPCurrent       int
-	Attack          int
-	Defense         int
-	Basic           int
-	Ballistic       int
-	Explosive       int
-	Kinetic         int
-	Electromagnetic int
-	Active          int
+	   Active is the currently selected weapon - index of damage type
+	   in DamageTypes (see damage.go).
+	   Ammo maps names of damage types to ramaining ammunition.
+	   Tags describes kind of creature (like "machine").
+	   Effects are status effects that creature is affected by
+	   (see effects.go).
+	   Range is the maximal distance of monster's ranged attack;
+	   AttackEffect is name of status effect that monster's attacks
+	   put on target (empty if none);
+	   Intent is action that monster plans for the next turn.
+	   Speed, SpeedVariance and Energy are used by turn scheduler
+	   (see scheduler.go); SpeedRoll is speed variance rolled for
+	   the next turn, valid if SpeedRolled is true;
+	   AmmoBonus and Passives are player's upgrades (see upgrades.go);
+	   Inventory holds consumables carried by player (see inventory.go). */
+	AIType        int
+	AITriggered   bool
+	HPMax         int
+	HPCurrent     int
+	Attack        int
+	Defense       int
+	Ammo          map[string]int
+	Active        int
+	Tags          []string
+	Effects       []StatusEffect
+	Range         int
+	AttackEffect  string
+	Intent        Intent
+	Speed         int
+	SpeedVariance int
+	SpeedRoll     int
+	SpeedRolled   bool
+	Energy        int
+	AmmoBonus     map[string]int
+	Passives      []string
+	Inventory     Objects
+}
+
+type AffinityProperties struct {
+	/* AffinityProperties describes how creature reacts
+	   to specific damage types. Weaknesses, Resistances and
+	   Immunities hold names of damage types (as defined
+	   in damage types data file). RandomWeaknesses is number of weaknesses
+	   that are rolled additionally during creature creation.
+	   Affinities is computed from all fields above; it maps
+	   damage type to affinity. */
+	Weaknesses       []string
+	Resistances      []string
+	Immunities       []string
+	RandomWeaknesses int
+	Affinities       map[string]int
 }
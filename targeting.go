@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	blt "bearlibterminal"
+)
+
+/* Targeting holds state of two-step targeting (enabled by
+   TARGETING_PREVIEW option): the first attack key press shows
+   the line of fire, the second press in the same direction fires. */
+var Targeting = struct {
+	Active bool
+	DX, DY int
+}{}
+
+func (c *Creature) Aim(dx, dy int, b Board, cs Creatures) bool {
+	/* Aim is used by attack actions instead of Shoot. If targeting
+	   preview is disabled, or the same direction is confirmed,
+	   receiver shoots. Otherwise, preview is shown, and turn is
+	   not spent. */
+	if TargetingPreview == false ||
+		(Targeting.Active == true && Targeting.DX == dx && Targeting.DY == dy) {
+		Targeting.Active = false
+		return c.Shoot(dx, dy, b, cs)
+	}
+	Targeting.Active = true
+	Targeting.DX, Targeting.DY = dx, dy
+	return false
+}
+
+func PrintTargeting(b Board, c Creatures) {
+	/* Function PrintTargeting draws line of fire of player's active
+	   weapon, in direction stored in Targeting. Path is green if
+	   target is weak to active damage type, red if it resists it,
+	   is immune, or there is no ammo. Tile that stops the shot
+	   without hitting anyone is marked by X. Short summary is
+	   printed in place of message log. */
+	if Targeting.Active == false {
+		return
+	}
+	p := c[0]
+	if p.Active < 0 || p.Active >= len(DamageTypes) {
+		return
+	}
+	dt := DamageTypes[p.Active]
+	vec := FireVector(p.X, p.Y, Targeting.DX, Targeting.DY, MapSizeX+MapSizeY)
+	TraceVector(vec, b, c)
+	var target *Creature
+	if len(vec.Hits) > 0 {
+		target = vec.Hits[0]
+	}
+	color := VectorColorNeutral
+	hint := "No target."
+	if target != nil {
+		hint = target.Name + ": "
+		switch target.Affinities[dt.Name] {
+		case AffinityWeak:
+			color = VectorColorGood
+			hint = hint + "weak to " + dt.Name + "!"
+		case AffinityResistant:
+			color = VectorColorBad
+			hint = hint + "resists " + dt.Name + "."
+		case AffinityImmune:
+			color = VectorColorBad
+			hint = hint + "immune to " + dt.Name + "."
+		default:
+			hint = hint + "not weak to " + dt.Name + "."
+		}
+	}
+	if p.Ammo[dt.Name] <= 0 {
+		color = VectorColorBad
+		hint = "No " + dt.Name + " ammo."
+	}
+	for i := range vec.TilesX {
+		x, y := vec.TilesX[i], vec.TilesY[i]
+		if x == p.X && y == p.Y {
+			continue
+		}
+		if b[x][y].Blocked == true {
+			if target == nil {
+				PrintRangedCharacter(x, y, VectorColorBad, false)
+			}
+			break
+		}
+		PrintRangedCharacter(x, y, color, true)
+		if target != nil && x == target.X && y == target.Y &&
+			dt.Behavior != BehaviorPierce {
+			break
+		}
+	}
+	blt.Layer(UILayer)
+	blt.ClearArea(LogPosX, LogPosY, LogSizeX, LogSizeY)
+	for i, v := range WrapText(hint+" Confirm to fire.", LogSizeX) {
+		if i >= LogSizeY {
+			break
+		}
+		blt.Print(LogPosX, LogPosY+i, "[color="+color+"]"+
+			EscapeBltString(v)+"[/color]")
+	}
+}
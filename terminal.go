@@ -35,18 +35,29 @@ import (
 
 const (
 	// Setting BearLibTerminal window.
-	WindowSizeX = 14
-	WindowSizeY = 14
-	MapSizeX    = 12
-	MapSizeY    = 12
-	UIPosX      = 0
-	UIPosY      = MapSizeY
-	UISizeX     = WindowSizeX
-	UISizeY     = WindowSizeY - MapSizeY
-	GameTitle   = "Broughlike"
-	GameVersion = "0.1"
-	FontName    = "Deferral-Square.ttf"
-	FontSize    = 24
+	// Sidebar is the column of UI on the right of map; every damage type
+	// uses one column of it, so it limits number of damage types.
+	// Message log strip is placed under the UI.
+	MapSizeX     = 12
+	MapSizeY     = 12
+	SidebarSizeX = 6
+	WindowSizeX  = MapSizeX + SidebarSizeX
+	WindowSizeY  = MapSizeY + UISizeY + LogSizeY
+	UIPosX       = 0
+	UIPosY       = MapSizeY
+	UISizeX      = WindowSizeX
+	UISizeY      = 2
+	LogPosX      = 0
+	LogPosY      = UIPosY + UISizeY
+	LogSizeX     = WindowSizeX
+	LogSizeY     = 2
+	GameTitle    = "Broughlike"
+	GameVersion  = "0.1"
+	FontName     = "Deferral-Square.ttf"
+	FontSize     = 24
+	// Small font is used for effect icons drawn in corner of tile.
+	SmallFontSize    = FontSize / 2
+	EffectIconOffset = "12,-2"
 )
 
 var TerminalSeed = ""
@@ -62,11 +73,19 @@ func InitializeBLT() {
 	/* Constraining threads and setting BearLibTerminal window. */
 	constrainThreads()
 	blt.Open()
+	Headless = false
 	sizeX, sizeY := strconv.Itoa(WindowSizeX), strconv.Itoa(WindowSizeY)
 	sizeFont := strconv.Itoa(FontSize)
 	window := "window: size=" + sizeX + "x" + sizeY
 	blt.Set(window + ", title=' " + GameTitle + " " + GameVersion +
 		" " + TerminalSeed + "'; font: " + FontName + ", size=" + sizeFont)
+	blt.Set("small font: " + FontName + ", size=" + strconv.Itoa(SmallFontSize))
 	blt.Clear()
 	blt.Refresh()
 }
+
+func SetWindowTitle() {
+	/* SetWindowTitle updates title of window, ie after seed change. */
+	blt.Set("window: title=' " + GameTitle + " " + GameVersion +
+		" " + TerminalSeed + "'")
+}
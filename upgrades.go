@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	blt "bearlibterminal"
+)
+
+const (
+	// Kinds of upgrades, as written in json file.
+	UpgradeHP      = "hp"
+	UpgradeAmmo    = "ammo"
+	UpgradeAttack  = "attack"
+	UpgradePassive = "passive"
+)
+
+const (
+	/* Passive abilities. Every passive may be taken only once.
+	   Scavenger obtains one ammo more from every resource;
+	   field medic restores one HP more with every medkit. */
+	PassiveScavenger  = "scavenger"
+	PassiveFieldMedic = "field medic"
+)
+
+// Number of upgrades offered between levels.
+const UpgradeChoices = 3
+
+/* PendingUpgrade is offer that player has not chosen from yet.
+   It is part of saved run, so offer survives closing the game. */
+var PendingUpgrade = []Upgrade{}
+
+type Upgrade struct {
+	/* Upgrades are offered to player on descending stairs.
+	   Kind is one of Upgrade* constants; Amount is used by
+	   hp, ammo and attack upgrades. Ammo upgrade with empty
+	   DamageType picks random damage type when offered.
+	   Passive is name of one of Passive* constants. */
+	Name        string
+	Description string
+	Kind        string
+	Amount      int
+	DamageType  string
+	Passive     string
+}
+
+var Upgrades = []Upgrade{}
+
+func InitializeUpgrades() {
+	/* Function InitializeUpgrades reads pool of upgrades at the start
+	   of the game. There is lazy panic for json errors; other errors
+	   are only printed. */
+	err := UpgradesFromJson(UpgradesPathJson, &Upgrades)
+	if err != nil {
+		fmt.Println(err)
+		panic(-1)
+	}
+	for _, v := range Upgrades {
+		switch v.Kind {
+		case UpgradeHP, UpgradeAttack, UpgradePassive:
+		case UpgradeAmmo:
+			if _, ok := DamageTypeByName(v.DamageType); v.DamageType != "" && ok == false {
+				txt := DamageTypeNameError(v.DamageType)
+				fmt.Println(errors.New("Upgrade uses unknown damage type." + txt))
+			}
+		default:
+			txt := UpgradeKindError(v.Name, v.Kind)
+			fmt.Println(errors.New("Unknown kind of upgrade." + txt))
+		}
+	}
+}
+
+func (c *Creature) UpgradeOffer(n int) []Upgrade {
+	/* UpgradeOffer returns up to "n" random upgrades, without
+	   repetitions. Passives that receiver has already are skipped. */
+	var pool = []Upgrade{}
+	for _, v := range Upgrades {
+		if v.Kind == UpgradePassive && c.HasPassive(v.Passive) == true {
+			continue
+		}
+		if v.Kind == UpgradeAmmo && v.DamageType == "" && len(DamageTypes) > 0 {
+			v.DamageType = DamageTypes[rand.Intn(len(DamageTypes))].Name
+		}
+		pool = append(pool, v)
+	}
+	rand.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+	if len(pool) > n {
+		pool = pool[:n]
+	}
+	return pool
+}
+
+func (c *Creature) ApplyUpgrade(u Upgrade) {
+	/* ApplyUpgrade modifies receiver according to chosen upgrade.
+	   New max HP is healed immediately. */
+	switch u.Kind {
+	case UpgradeHP:
+		c.HPMax += u.Amount
+		c.HPCurrent += u.Amount
+	case UpgradeAmmo:
+		if c.AmmoBonus == nil {
+			c.AmmoBonus = map[string]int{}
+		}
+		c.AmmoBonus[u.DamageType] += u.Amount
+	case UpgradeAttack:
+		c.Attack += u.Amount
+	case UpgradePassive:
+		c.Passives = append(c.Passives, u.Passive)
+	}
+}
+
+func (c *Creature) HasPassive(name string) bool {
+	/* HasPassive checks if receiver has passive ability. */
+	for _, v := range c.Passives {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Creature) AmmoCap(name string) int {
+	/* AmmoCap returns maximal amount of ammo of given damage type,
+	   including upgrades. */
+	return AmmoMax + c.AmmoBonus[name]
+}
+
+func ChooseUpgrade(p *Creature) {
+	/* Function ChooseUpgrade shows upgrade screen with PendingUpgrade,
+	   and waits for player choice. Choice can not be skipped, unless
+	   game window is closed - then offer stays pending, and close
+	   event is left in input queue (see PeekClose). */
+	for len(PendingUpgrade) > 0 {
+		UpgradeScreen(PendingUpgrade)
+		if PeekClose() == true {
+			return
+		}
+		key := ReadInput()
+		if key >= blt.TK_1 && key < blt.TK_1+len(PendingUpgrade) {
+			u := PendingUpgrade[key-blt.TK_1]
+			p.ApplyUpgrade(u)
+			AddMessage("you gain "+u.Name+".", MessageColorGood)
+			PendingUpgrade = []Upgrade{}
+		}
+	}
+}
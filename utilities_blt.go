@@ -29,6 +29,7 @@ package main
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	blt "bearlibterminal"
@@ -95,3 +96,11 @@ func SimplePutExt(x, y, dx, dy int, s string,
 	blt.PutExt(x, y, dx, dy, int(([]rune(s))[0]),
 		[4]uint32{color1, color2, color3, color4})
 }
+
+func EscapeBltString(s string) string {
+	/* EscapeBltString doubles "[" and "]" characters, so text
+	   (like messages) is printed literally, not parsed as BLT markup. */
+	s = strings.Replace(s, "[", "[[", -1)
+	s = strings.Replace(s, "]", "]]", -1)
+	return s
+}
@@ -122,6 +122,17 @@ func AbsoluteValue(i int) int {
 	return i
 }
 
+func Sign(i int) int {
+	/* Function Sign returns -1 for negative values, 1 for positive
+	   values, and 0 for 0. */
+	if i < 0 {
+		return -1
+	} else if i > 0 {
+		return 1
+	}
+	return 0
+}
+
 func ReverseIntSlice(arr []int) []int {
 	/* Function ReverseIntSlice takes slice of int and returns
 	   it in reversed order. It is odd that "battery included"
@@ -46,14 +46,19 @@ type Vector struct {
 	   and slice of bools.
 	   These bools should be set to false by default.
 	   For every passable tile from Start to Target,
-	   one bool will be changed to true. */
-	StartX  int
-	StartY  int
-	TargetX int
-	TargetY int
-	Values  []bool
-	TilesX  []int
-	TilesY  []int
+	   one bool will be changed to true.
+	   Hits and Obstacle are filled by TraceVector:
+	   Hits are all creatures on the line, in order,
+	   and Obstacle is the first blocking tile. */
+	StartX   int
+	StartY   int
+	TargetX  int
+	TargetY  int
+	Values   []bool
+	TilesX   []int
+	TilesY   []int
+	Hits     Creatures
+	Obstacle *Tile
 }
 
 func NewVector(sx, sy, tx, ty int) (*Vector, error) {
@@ -69,7 +74,7 @@ func NewVector(sx, sy, tx, ty int) (*Vector, error) {
 	length := DistanceBetween(sx, sy, tx, ty)
 	values := make([]bool, length+1)
 	newVector := &Vector{sx, sy, tx, ty, values,
-		[]int{}, []int{}}
+		[]int{}, []int{}, Creatures{}, nil}
 	return newVector, err
 }
 
@@ -181,7 +186,8 @@ func ExtrapolateVector(vec *Vector, dx, dy []int) *Vector {
 	}
 	values := make([]bool, len(newTilesX)+1)
 	newVector := &Vector{vec.StartY, vec.StartX,
-		vec.TargetX, vec.TargetY, values, newTilesX, newTilesY}
+		vec.TargetX, vec.TargetY, values, newTilesX, newTilesY,
+		Creatures{}, nil}
 	return newVector
 }
 
@@ -226,6 +232,36 @@ Loop:
 	return valid, tile, monster
 }
 
+func TraceVector(vec *Vector, b Board, c Creatures) {
+	/* Function TraceVector is successor of ValidateVector that
+	   does not stop on the first creature. It walks along the whole
+	   vector, until the first blocked tile, and stores every
+	   blocking creature met on the way in vec.Hits (ordered from
+	   start to target), and that tile in vec.Obstacle.
+	   Values are set the same way as in ValidateVector, but
+	   tiles occupied by creatures are marked as passable. */
+	vec.Hits = Creatures{}
+	vec.Obstacle = nil
+	for i := 0; i < len(vec.TilesX); i++ {
+		x, y := vec.TilesX[i], vec.TilesY[i]
+		if x == vec.StartX && y == vec.StartY {
+			continue
+		}
+		if b[x][y].Blocked == true {
+			vec.Obstacle = b[x][y]
+			break
+		}
+		for j := 0; j < len(c); j++ {
+			if x == c[j].X && y == c[j].Y && c[j].Blocked == true {
+				vec.Hits = append(vec.Hits, c[j])
+			}
+		}
+		if i < len(vec.Values) {
+			vec.Values[i] = true
+		}
+	}
+}
+
 func PrintRangedCharacter(x, y int, color string, valid bool) {
 	blt.Layer(LookLayer)
 	if valid == true {
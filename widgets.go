@@ -0,0 +1,297 @@
+/*
+Copyright (c) 2018, Tomasz "VedVid" Nowakowski
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	blt "bearlibterminal"
+)
+
+const (
+	// Colors used by widgets.
+	WidgetColorText   = "gray"
+	WidgetColorActive = "white"
+	WidgetColorFrame  = "dark gray"
+	WidgetColorHint   = "dark gray"
+)
+
+const (
+	// Box drawing characters.
+	BoxHorizontal  = "─"
+	BoxVertical    = "│"
+	BoxTopLeft     = "┌"
+	BoxTopRight    = "┐"
+	BoxBottomLeft  = "└"
+	BoxBottomRight = "┘"
+)
+
+func DrawBox(x, y, w, h int, title string) {
+	/* Function DrawBox clears area on UILayer, and draws frame
+	   around it. Title (if any) is printed in the top border.
+	   Content of box starts at x+1, y+1. */
+	blt.Layer(UILayer)
+	blt.ClearArea(x, y, w, h)
+	color := "[color=" + WidgetColorFrame + "]"
+	for i := x + 1; i < x+w-1; i++ {
+		blt.Print(i, y, color+BoxHorizontal+"[/color]")
+		blt.Print(i, y+h-1, color+BoxHorizontal+"[/color]")
+	}
+	for j := y + 1; j < y+h-1; j++ {
+		blt.Print(x, j, color+BoxVertical+"[/color]")
+		blt.Print(x+w-1, j, color+BoxVertical+"[/color]")
+	}
+	blt.Print(x, y, color+BoxTopLeft+"[/color]")
+	blt.Print(x+w-1, y, color+BoxTopRight+"[/color]")
+	blt.Print(x, y+h-1, color+BoxBottomLeft+"[/color]")
+	blt.Print(x+w-1, y+h-1, color+BoxBottomRight+"[/color]")
+	if title != "" {
+		blt.Print(x+1, y, "[color="+WidgetColorActive+"]"+
+			EscapeBltString(title)+"[/color]")
+	}
+}
+
+func PrintHint(y int, txt string) {
+	/* Function PrintHint prints short, dimmed, help text
+	   (like "Esc: back") in small font. */
+	blt.Layer(UILayer)
+	blt.Print(1, y, "[font=small][color="+WidgetColorHint+"]"+
+		txt+"[/color][/font]")
+}
+
+type ListMenu struct {
+	/* ListMenu is vertical list of entries, navigated with arrows
+	   (or numpad). Values, if present, are printed right-aligned,
+	   next to entries. Entries are centered if Centered is true;
+	   Spacing is distance between rows. */
+	Items    []string
+	Values   []string
+	Cursor   int
+	X, Y, W  int
+	Spacing  int
+	Centered bool
+}
+
+func (m *ListMenu) Draw() {
+	/* Draw prints menu; selected entry is highlighted. */
+	blt.Layer(UILayer)
+	if m.Cursor >= len(m.Items) {
+		m.Cursor = len(m.Items) - 1
+	}
+	spacing := m.Spacing
+	if spacing < 1 {
+		spacing = 1
+	}
+	for i, v := range m.Items {
+		color := WidgetColorText
+		if i == m.Cursor {
+			color = WidgetColorActive
+			if m.Centered == true {
+				v = "> " + v + " <"
+			}
+		}
+		y := m.Y + i*spacing
+		txt := "[color=" + color + "]" + EscapeBltString(v) + "[/color]"
+		if m.Centered == true {
+			blt.Print(m.X+(m.W-utf8.RuneCountInString(v))/2, y, txt)
+		} else {
+			blt.Print(m.X, y, txt)
+		}
+		if i < len(m.Values) {
+			value := m.Values[i]
+			blt.Print(m.X+m.W-utf8.RuneCountInString(value), y,
+				"[color="+color+"]"+EscapeBltString(value)+"[/color]")
+		}
+	}
+}
+
+func (m *ListMenu) HandleKey(key int) bool {
+	/* HandleKey moves cursor. Returns true if entry is chosen. */
+	if len(m.Items) == 0 {
+		return false
+	}
+	switch key {
+	case blt.TK_UP, blt.TK_KP_8:
+		m.Cursor = (m.Cursor - 1 + len(m.Items)) % len(m.Items)
+	case blt.TK_DOWN, blt.TK_KP_2:
+		m.Cursor = (m.Cursor + 1) % len(m.Items)
+	case blt.TK_HOME:
+		m.Cursor = 0
+	case blt.TK_END:
+		m.Cursor = len(m.Items) - 1
+	case blt.TK_RETURN, blt.TK_KP_ENTER:
+		return true
+	}
+	return false
+}
+
+type TextInput struct {
+	/* TextInput is single-line text field. It uses characters
+	   (not scancodes) of pressed keys, so typed text does not depend
+	   on keyboard layout; special keys are read the same way as
+	   HardcodedKeys. */
+	Text      string
+	MaxLength int
+	X, Y      int
+}
+
+func (t *TextInput) Draw() {
+	/* Draw prints text with cursor at its end. */
+	blt.Layer(UILayer)
+	blt.Print(t.X, t.Y, "[color="+WidgetColorActive+"]"+
+		EscapeBltString(t.Text)+"[/color][color="+WidgetColorText+"]_[/color]")
+}
+
+func (t *TextInput) HandleKey(key int) (bool, bool) {
+	/* HandleKey takes key read by blt.Read (not ReadInput - it
+	   needs character of key, not its QWERTY scancode).
+	   Returns two flags: input is confirmed (Enter), and input
+	   is cancelled (Escape or closing window). */
+	switch key {
+	case blt.TK_RETURN, blt.TK_KP_ENTER:
+		return true, false
+	case blt.TK_ESCAPE, blt.TK_CLOSE:
+		return false, true
+	case blt.TK_BACKSPACE:
+		if len(t.Text) > 0 {
+			r := []rune(t.Text)
+			t.Text = string(r[:len(r)-1])
+		}
+		return false, false
+	}
+	if blt.Check(blt.TK_WCHAR) == 0 {
+		return false, false
+	}
+	r := rune(blt.State(blt.TK_WCHAR))
+	_, mapped := KeyMap[r]
+	if mapped == false && unicode.IsLetter(r) == false &&
+		unicode.IsDigit(r) == false && r != ' ' {
+		return false, false
+	}
+	if t.MaxLength > 0 && utf8.RuneCountInString(t.Text) >= t.MaxLength {
+		return false, false
+	}
+	t.Text = t.Text + string(r)
+	return false, false
+}
+
+type TextPane struct {
+	/* TextPane is scrollable area of text. Lines are wrapped
+	   to pane width; every line has its own color. */
+	Lines  []string
+	Colors []string
+	Offset int
+	X, Y   int
+	W, H   int
+}
+
+func (p *TextPane) AddLine(txt, color string) {
+	/* AddLine wraps text to pane width, and appends it. */
+	for _, v := range WrapText(txt, p.W) {
+		p.Lines = append(p.Lines, v)
+		p.Colors = append(p.Colors, color)
+	}
+}
+
+func (p *TextPane) maxOffset() int {
+	offset := len(p.Lines) - p.H
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+func (p *TextPane) ScrollToEnd() {
+	/* ScrollToEnd shows the last lines of text. */
+	p.Offset = p.maxOffset()
+}
+
+func (p *TextPane) Draw() {
+	/* Draw prints visible lines of text. */
+	blt.Layer(UILayer)
+	for i := 0; i < p.H && p.Offset+i < len(p.Lines); i++ {
+		blt.Print(p.X, p.Y+i, "[color="+p.Colors[p.Offset+i]+"]"+
+			EscapeBltString(p.Lines[p.Offset+i])+"[/color]")
+	}
+}
+
+func (p *TextPane) HandleKey(key int) bool {
+	/* HandleKey scrolls text with arrows, page up / page down,
+	   home / end. Returns true if key was used. */
+	switch key {
+	case blt.TK_UP, blt.TK_KP_8:
+		p.Offset--
+	case blt.TK_DOWN, blt.TK_KP_2:
+		p.Offset++
+	case blt.TK_PAGEUP, blt.TK_KP_9:
+		p.Offset -= p.H
+	case blt.TK_PAGEDOWN, blt.TK_KP_3:
+		p.Offset += p.H
+	case blt.TK_HOME:
+		p.Offset = 0
+	case blt.TK_END:
+		p.Offset = p.maxOffset()
+	default:
+		return false
+	}
+	if p.Offset > p.maxOffset() {
+		p.Offset = p.maxOffset()
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	return true
+}
+
+func Confirm(question string) bool {
+	/* Function Confirm shows yes / no dialog over the current screen,
+	   and waits for answer. Y or Enter means yes; N, Escape, or
+	   closing window means no. Close event is not consumed, so
+	   caller still sees it. */
+	lines := WrapText(question, WindowSizeX-2)
+	h := len(lines) + 4
+	y := (WindowSizeY - h) / 2
+	DrawBox(0, y, WindowSizeX, h, "")
+	for i, v := range lines {
+		blt.Print(1, y+1+i, "[color="+WidgetColorActive+"]"+
+			EscapeBltString(v)+"[/color]")
+	}
+	blt.Print(1, y+h-2, "[color="+WidgetColorText+"](Y)es / (N)o[/color]")
+	blt.Refresh()
+	for {
+		if PeekClose() == true {
+			return false
+		}
+		switch ReadInput() {
+		case blt.TK_Y, blt.TK_RETURN, blt.TK_KP_ENTER:
+			return true
+		case blt.TK_N, blt.TK_ESCAPE:
+			return false
+		}
+	}
+}